@@ -9,18 +9,20 @@ import (
 // POST /v1/{practiceid}/appointments/{appointmentid}/cancelcheckin
 // https://docs.athenahealth.com/api/api-ref/appointment-check-in#Cancel-appointment-check-in-process
 func (h *HTTPClient) AppointmentCancelCheckIn(ctx context.Context, apptID string) error {
+	ctx = contextWithAuditOperation(ctx, "AppointmentCancelCheckIn")
+
 	if apptID == "" {
 		return fmt.Errorf("cannot AppointmentCancelCheckIn with empty apptID [%s]", apptID)
 	}
 
 	out := MessageResponse{}
-	_, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/cancelcheckin", apptID), nil, &out)
+	res, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/cancelcheckin", apptID), nil, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -30,18 +32,20 @@ func (h *HTTPClient) AppointmentCancelCheckIn(ctx context.Context, apptID string
 // POST /v1/{practiceid}/appointments/{appointmentid}/checkin
 // https://docs.athenahealth.com/api/api-ref/appointment-check-in#Check-in-this-appointment.
 func (h *HTTPClient) AppointmentCheckIn(ctx context.Context, apptID string) error {
+	ctx = contextWithAuditOperation(ctx, "AppointmentCheckIn")
+
 	if apptID == "" {
 		return fmt.Errorf("cannot AppointmentCheckIn with empty apptID [%s]", apptID)
 	}
 
 	out := MessageResponse{}
-	_, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/checkin", apptID), nil, &out)
+	res, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/checkin", apptID), nil, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -51,18 +55,20 @@ func (h *HTTPClient) AppointmentCheckIn(ctx context.Context, apptID string) erro
 // POST /v1/{practiceid}/appointments/{appointmentid}/checkout
 // https://docs.athenahealth.com/api/api-ref/check-out#Complete-appointment-check-out-process
 func (h *HTTPClient) AppointmentCheckOut(ctx context.Context, apptID string) error {
+	ctx = contextWithAuditOperation(ctx, "AppointmentCheckOut")
+
 	if apptID == "" {
 		return fmt.Errorf("cannot AppointmentCheckOut with empty apptID [%s]", apptID)
 	}
 
 	out := ErrorMessageResponse{}
-	_, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/checkout", apptID), nil, &out)
+	res, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/checkout", apptID), nil, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -72,18 +78,20 @@ func (h *HTTPClient) AppointmentCheckOut(ctx context.Context, apptID string) err
 // POST /v1/{practiceid}/appointments/{appointmentid}/startcheckin
 // https://docs.athenahealth.com/api/api-ref/appointment-check-in#Initiate-appointment-check-in-process
 func (h *HTTPClient) AppointmentStartCheckIn(ctx context.Context, apptID string) error {
+	ctx = contextWithAuditOperation(ctx, "AppointmentStartCheckIn")
+
 	if apptID == "" {
 		return fmt.Errorf("cannot AppointmentStartCheckIn with empty apptID [%s]", apptID)
 	}
 
 	out := MessageResponse{}
-	_, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/startcheckin", apptID), nil, &out)
+	res, err := h.Post(ctx, fmt.Sprintf("/appointments/%s/startcheckin", apptID), nil, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
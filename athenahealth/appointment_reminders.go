@@ -42,6 +42,12 @@ type ListAppointmentRemindersResult struct {
 	Pagination *PaginationResult
 }
 
+type listAppointmentRemindersResponse struct {
+	Reminders []AppointmentReminder `json:"reminders"`
+
+	PaginationResponse
+}
+
 // ListAppointmentReminders - Retrieves a list of appointment reminders with the specified departmentid and
 // approximatedate within the given date range
 //
@@ -49,6 +55,8 @@ type ListAppointmentRemindersResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-reminders#Get-list-of-appointment-reminders
 func (h *HTTPClient) ListAppointmentReminders(ctx context.Context, opts *ListAppointmentRemindersOptions) (*ListAppointmentRemindersResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListAppointmentReminders")
+
 	if len(opts.DepartmentID) == 0 {
 		return nil, errors.New("missing DepartmentID")
 	}
@@ -59,7 +67,7 @@ func (h *HTTPClient) ListAppointmentReminders(ctx context.Context, opts *ListApp
 		return nil, errors.New("missing EndDate")
 	}
 
-	out := &ListAppointmentRemindersResult{}
+	out := &listAppointmentRemindersResponse{}
 
 	q := url.Values{}
 
@@ -80,10 +88,37 @@ func (h *HTTPClient) ListAppointmentReminders(ctx context.Context, opts *ListApp
 		q.Add("showdeleted", strconv.FormatBool(*opts.ShowDeleted))
 	}
 
-	_, err := h.Get(ctx, "/appointments/appointmentreminders", q, &out)
+	if opts.Pagination != nil {
+		if opts.Pagination.Limit > 0 {
+			q.Add("limit", strconv.Itoa(opts.Pagination.Limit))
+		}
+
+		if opts.Pagination.Offset > 0 {
+			q.Add("offset", strconv.Itoa(opts.Pagination.Offset))
+		}
+	}
+
+	_, err := h.Get(ctx, "/appointments/appointmentreminders", q, out)
 	if err != nil {
 		return nil, err
 	}
 
-	return out, nil
+	return &ListAppointmentRemindersResult{
+		Reminders:  out.Reminders,
+		Pagination: makePaginationResult(out.Next, out.Previous, out.TotalCount),
+	}, nil
+}
+
+// ListAppointmentRemindersPager returns a Pager over the appointment reminders returned by ListAppointmentReminders.
+func (h *HTTPClient) ListAppointmentRemindersPager(opts *ListAppointmentRemindersOptions) *Pager[AppointmentReminder] {
+	return newPager(h, "ListAppointmentRemindersPager", func(ctx context.Context) ([]AppointmentReminder, *PaginationResult, error) {
+		res, err := h.ListAppointmentReminders(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Reminders, res.Pagination, nil
+	}, func(out *listAppointmentRemindersResponse) ([]AppointmentReminder, *PaginationResult) {
+		return out.Reminders, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
 }
@@ -24,6 +24,8 @@ type CreateAppointmentSlotResult struct {
 // POST /v1/{practiceid}/appointments/open
 // https://docs.athenahealth.com/api/api-ref/appointment-slot#Create-a-new-appointment-slot
 func (h *HTTPClient) CreateAppointmentSlot(ctx context.Context, opts *CreateAppointmentSlotOptions) (*CreateAppointmentSlotResult, error) {
+	ctx = contextWithAuditOperation(ctx, "CreateAppointmentSlot")
+
 	out := CreateAppointmentSlotResult{}
 
 	q := url.Values{}
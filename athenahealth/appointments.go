@@ -33,6 +33,8 @@ type Appointment struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment#Get-appointment-details
 func (h *HTTPClient) GetAppointment(ctx context.Context, id string) (*Appointment, error) {
+	ctx = contextWithAuditOperation(ctx, "GetAppointment")
+
 	out := []*Appointment{}
 
 	_, err := h.Get(ctx, fmt.Sprintf("/appointments/%s", id), nil, &out)
@@ -71,6 +73,8 @@ type listAppointmentCustomFieldsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-custom-fields#Get-the-list-of-appointment-custom-fields
 func (h *HTTPClient) ListAppointmentCustomFields(ctx context.Context) ([]*AppointmentCustomField, error) {
+	ctx = contextWithAuditOperation(ctx, "ListAppointmentCustomFields")
+
 	out := &listAppointmentCustomFieldsResponse{}
 
 	_, err := h.Get(ctx, "/appointments/customfields", nil, &out)
@@ -193,6 +197,8 @@ type listBookedAppointmentsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment#Get-list-of-booked-appointments
 func (h *HTTPClient) ListBookedAppointments(ctx context.Context, opts *ListBookedAppointmentsOptions) (*ListBookedAppointmentsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListBookedAppointments")
+
 	out := &listBookedAppointmentsResponse{}
 
 	q := url.Values{}
@@ -248,6 +254,43 @@ func (h *HTTPClient) ListBookedAppointments(ctx context.Context, opts *ListBooke
 	}, nil
 }
 
+// ListBookedAppointmentsPager returns a Pager over the booked appointments returned by ListBookedAppointments.
+func (h *HTTPClient) ListBookedAppointmentsPager(opts *ListBookedAppointmentsOptions) *Pager[*BookedAppointment] {
+	return newPager(h, "ListBookedAppointmentsPager", func(ctx context.Context) ([]*BookedAppointment, *PaginationResult, error) {
+		res, err := h.ListBookedAppointments(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.BookedAppointments, res.Pagination, nil
+	}, func(out *listBookedAppointmentsResponse) ([]*BookedAppointment, *PaginationResult) {
+		return out.Appointments, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
+// ListBookedAppointmentsBulk returns every booked appointment returned by ListBookedAppointments, fetching
+// the pages after the first concurrently. See BulkListOptions.
+func (h *HTTPClient) ListBookedAppointmentsBulk(ctx context.Context, opts *ListBookedAppointmentsOptions, bulkOpts *BulkListOptions) ([]*BookedAppointment, error) {
+	ctx = contextWithAuditOperation(ctx, "ListBookedAppointmentsBulk")
+
+	pageOpts := ListBookedAppointmentsOptions{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+
+	return bulkList(ctx, pageOpts.Pagination, bulkOpts, func(ctx context.Context, pagination *PaginationOptions) ([]*BookedAppointment, *PaginationResult, error) {
+		pageOpts := pageOpts
+		pageOpts.Pagination = pagination
+
+		res, err := h.ListBookedAppointments(ctx, &pageOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.BookedAppointments, res.Pagination, nil
+	})
+}
+
 type ListChangedAppointmentsOptions struct {
 	DepartmentID               string
 	LeaveUnprocessed           bool
@@ -268,6 +311,8 @@ type listChangedAppointmentsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment#Get-list-of-changes-in-appointment-slots-based-on-subscribed-events
 func (h *HTTPClient) ListChangedAppointments(ctx context.Context, opts *ListChangedAppointmentsOptions) ([]*BookedAppointment, error) {
+	ctx = contextWithAuditOperation(ctx, "ListChangedAppointments")
+
 	out := &listChangedAppointmentsResponse{}
 
 	q := url.Values{}
@@ -322,6 +367,8 @@ type CreateAppointmentNoteOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-notes#Create-appointment-note
 func (h *HTTPClient) CreateAppointmentNote(ctx context.Context, appointmentID string, opts *CreateAppointmentNoteOptions) error {
+	ctx = contextWithAuditOperation(ctx, "CreateAppointmentNote")
+
 	var form url.Values
 
 	if opts != nil {
@@ -340,7 +387,11 @@ func (h *HTTPClient) CreateAppointmentNote(ctx context.Context, appointmentID st
 		}
 	}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/appointments/%s/notes", appointmentID), form, nil)
+	_, err := idempotent(ctx, h, "CreateAppointmentNote", func(ctx context.Context) (struct{}, error) {
+		_, err := h.PostForm(ctx, fmt.Sprintf("/appointments/%s/notes", appointmentID), form, nil)
+
+		return struct{}{}, err
+	})
 	if err != nil {
 		return err
 	}
@@ -371,6 +422,8 @@ type listAppointmentNotesResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-notes#Get-all-appointment-notes
 func (h *HTTPClient) ListAppointmentNotes(ctx context.Context, appointmentID string, opts *ListAppointmentNotesOptions) ([]*AppointmentNote, error) {
+	ctx = contextWithAuditOperation(ctx, "ListAppointmentNotes")
+
 	out := &listAppointmentNotesResponse{}
 
 	q := url.Values{}
@@ -406,6 +459,8 @@ type UpdateAppointmentNoteOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-notes#Update-appointment-note
 func (h *HTTPClient) UpdateAppointmentNote(ctx context.Context, appointmentID, noteID string, opts *UpdateAppointmentNoteOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UpdateAppointmentNote")
+
 	var form url.Values
 
 	if opts != nil {
@@ -447,6 +502,8 @@ type DeleteAppointmentNoteOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-notes#Delete-appointment-note
 func (h *HTTPClient) DeleteAppointmentNote(ctx context.Context, appointmentID, noteID string, opts *DeleteAppointmentNoteOptions) error {
+	ctx = contextWithAuditOperation(ctx, "DeleteAppointmentNote")
+
 	var form url.Values
 
 	if opts != nil {
@@ -533,6 +590,8 @@ type ListOpenAppointmentSlotsResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment-slot#Get-list-of-open-appointment-slots
 func (h *HTTPClient) ListOpenAppointmentSlots(ctx context.Context, departmentID int, opts *ListOpenAppointmentSlotOptions) (*ListOpenAppointmentSlotsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListOpenAppointmentSlots")
+
 	out := &listOpenAppointmentSlotsResponse{}
 
 	q := url.Values{}
@@ -602,6 +661,20 @@ func (h *HTTPClient) ListOpenAppointmentSlots(ctx context.Context, departmentID
 	}, nil
 }
 
+// ListOpenAppointmentSlotsPager returns a Pager over the open appointment slots returned by ListOpenAppointmentSlots.
+func (h *HTTPClient) ListOpenAppointmentSlotsPager(departmentID int, opts *ListOpenAppointmentSlotOptions) *Pager[*OpenAppointmentSlot] {
+	return newPager(h, "ListOpenAppointmentSlotsPager", func(ctx context.Context) ([]*OpenAppointmentSlot, *PaginationResult, error) {
+		res, err := h.ListOpenAppointmentSlots(ctx, departmentID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Appointments, res.Pagination, nil
+	}, func(out *listOpenAppointmentSlotsResponse) ([]*OpenAppointmentSlot, *PaginationResult) {
+		return out.Appointments, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
 type BookAppointmentOptions struct {
 	AppointmentTypeID           int
 	BookingNote                 string
@@ -619,6 +692,8 @@ type BookAppointmentOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/appointment#Book-appointment
 func (h *HTTPClient) BookAppointment(ctx context.Context, patientID, appointmentID string, opts *BookAppointmentOptions) (*BookedAppointment, error) {
+	ctx = contextWithAuditOperation(ctx, "BookAppointment")
+
 	var out []*BookedAppointment
 
 	form := url.Values{}
@@ -659,16 +734,18 @@ func (h *HTTPClient) BookAppointment(ctx context.Context, patientID, appointment
 		}
 	}
 
-	_, err := h.PutForm(ctx, fmt.Sprintf("/appointments/%s", appointmentID), form, &out)
-	if err != nil {
-		return nil, err
-	}
+	return idempotent(ctx, h, "BookAppointment", func(ctx context.Context) (*BookedAppointment, error) {
+		_, err := h.PutForm(ctx, fmt.Sprintf("/appointments/%s", appointmentID), form, &out)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(out) == 0 {
-		return nil, errors.New("unexpected length returned")
-	}
+		if len(out) == 0 {
+			return nil, errors.New("unexpected length returned")
+		}
 
-	return out[0], nil
+		return out[0], nil
+	})
 }
 
 type UpdateBookedAppointmentOptions struct {
@@ -690,6 +767,8 @@ var updateBookedApptSuccess = "1"
 // PUT /v1/{practiceid}/appointments/booked/{appointmentid}
 // https://docs.athenahealth.com/api/api-ref/appointment-booked#Appointment-Booked
 func (h *HTTPClient) UpdateBookedAppointment(ctx context.Context, appointmentID string, opts *UpdateBookedAppointmentOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UpdateBookedAppointment")
+
 	form := url.Values{}
 
 	if opts.AppointmentTypeID != nil {
@@ -709,13 +788,13 @@ func (h *HTTPClient) UpdateBookedAppointment(ctx context.Context, appointmentID
 	}
 
 	var statusRes NumberString
-	_, err := h.PutForm(ctx, fmt.Sprintf("/appointments/booked/%s", appointmentID), form, &statusRes)
+	res, err := h.PutForm(ctx, fmt.Sprintf("/appointments/booked/%s", appointmentID), form, &statusRes)
 	if err != nil {
 		return err
 	}
 
 	if string(statusRes) != updateBookedApptSuccess {
-		return errors.New(string(statusRes))
+		return rejectedError(res, string(statusRes))
 	}
 
 	return nil
@@ -804,6 +883,8 @@ type RescheduleAppointmentOptions struct {
 // PUT /v1/{practiceid}/appointments/{appointmentid}/reschedule
 // https://docs.athenahealth.com/api/api-ref/appointment#Reschedule-appointment
 func (h *HTTPClient) RescheduleAppointment(ctx context.Context, appointmentID int, opts *RescheduleAppointmentOptions) (*RescheduleAppointmentResult, error) {
+	ctx = contextWithAuditOperation(ctx, "RescheduleAppointment")
+
 	var out []*RescheduleAppointmentResult
 
 	q := url.Values{}
@@ -833,7 +914,9 @@ func (h *HTTPClient) RescheduleAppointment(ctx context.Context, appointmentID in
 		}
 	}
 
-	_, err := h.PutForm(ctx, fmt.Sprintf("/appointments/%d/reschedule", appointmentID), q, &out)
+	// Rescheduling again would cancel the new appointment's slot, so the PUT
+	// is not retried.
+	_, err := h.PutForm(contextWithNonIdempotent(ctx), fmt.Sprintf("/appointments/%d/reschedule", appointmentID), q, &out)
 
 	if err != nil {
 		return nil, err
@@ -867,21 +950,25 @@ func (h *HTTPClient) freezeOrUnfreezeAppointmentSlot(ctx context.Context, appoin
 		}
 	}
 
-	_, err := h.PutForm(ctx, fmt.Sprintf("/appointments/%s/freeze", appointmentID), q, &out)
+	// A replay would fail with "already frozen" (or unfrozen) after the first
+	// request succeeded, so the PUT is not retried.
+	res, err := h.PutForm(contextWithNonIdempotent(ctx), fmt.Sprintf("/appointments/%s/freeze", appointmentID), q, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
+		err := rejectedError(res, out.ErrorMessage).(*APIError)
+
 		if strings.Contains(out.ErrorMessage, "already frozen") {
-			return ErrAppointmentSlotAlreadyFrozen
+			err.Err = errors.Join(ErrAppointmentSlotAlreadyFrozen, err.Err)
 		}
 
 		if strings.Contains(out.ErrorMessage, "already unfrozen") {
-			return ErrAppointmentSlotAlreadyUnfrozen
+			err.Err = errors.Join(ErrAppointmentSlotAlreadyUnfrozen, err.Err)
 		}
 
-		return errors.New(out.ErrorMessage)
+		return err
 	}
 
 	return nil
@@ -891,6 +978,8 @@ func (h *HTTPClient) freezeOrUnfreezeAppointmentSlot(ctx context.Context, appoin
 // PUT /v1/{practiceid}/appointments/{appointmentid}/freeze
 // https://docs.athenahealth.com/api/api-ref/appointment-slot#Freeze-appointment-slot
 func (h *HTTPClient) FreezeAppointmentSlot(ctx context.Context, appointmentID string, opts *FreezeOrUnfreezeAppointmentSlotOptions) error {
+	ctx = contextWithAuditOperation(ctx, "FreezeAppointmentSlot")
+
 	return h.freezeOrUnfreezeAppointmentSlot(ctx, appointmentID, true, opts)
 }
 
@@ -898,5 +987,7 @@ func (h *HTTPClient) FreezeAppointmentSlot(ctx context.Context, appointmentID st
 // PUT /v1/{practiceid}/appointments/{appointmentid}/freeze
 // https://docs.athenahealth.com/api/api-ref/appointment-slot#Freeze-appointment-slot
 func (h *HTTPClient) UnfreezeAppointmentSlot(ctx context.Context, appointmentID string, opts *FreezeOrUnfreezeAppointmentSlotOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UnfreezeAppointmentSlot")
+
 	return h.freezeOrUnfreezeAppointmentSlot(ctx, appointmentID, false, opts)
 }
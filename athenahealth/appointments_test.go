@@ -365,8 +365,8 @@ func TestHTTPClient_UpdateBookedAppointment_StringResponse(t *testing.T) {
 	defer ts.Close()
 
 	updateErr := athenaClient.UpdateBookedAppointment(context.Background(), apptID, opts)
-	assert.Error(updateErr)
-	assert.Equal(updateErr.Error(), "Invalid PROVIDERID input")
+	assert.ErrorIs(updateErr, ErrValidation)
+	assert.ErrorContains(updateErr, "Invalid PROVIDERID input")
 }
 
 func TestHTTPClient_RescheduleAppointment(t *testing.T) {
@@ -499,6 +499,11 @@ func TestHTTPClient_FreezeAppointmentSlot(t *testing.T) {
 
 		if testCase.Error != nil {
 			assert.ErrorContains(err, testCase.Error.Error(), testCase.Msg)
+
+			if errors.Is(testCase.Error, ErrAppointmentSlotAlreadyFrozen) {
+				assert.ErrorIs(err, ErrAppointmentSlotAlreadyFrozen, testCase.Msg)
+				assert.ErrorIs(err, ErrConflict, testCase.Msg)
+			}
 		} else {
 			assert.NoError(err, testCase.Msg)
 		}
@@ -0,0 +1,274 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/audit"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+)
+
+// Auditor receives an audit event for every call that accesses patient data.
+type Auditor interface {
+	Audit(ctx context.Context, event *audit.Event) error
+}
+
+// auditedPathPrefixes are the paths of endpoints that return or change patient
+// data even when the request doesn't name a patient, e.g. searches and feeds.
+var auditedPathPrefixes = []string{
+	"/appointments",
+	"/chart",
+	"/claims",
+	"/labresults",
+	"/patients",
+	"/prescriptions",
+}
+
+// unauditedPathPrefixes are exceptions to auditedPathPrefixes that only return
+// practice configuration.
+var unauditedPathPrefixes = []string{
+	"/chart/configuration",
+}
+
+// unauditedPaths are exceptions to auditedPathPrefixes that only return
+// practice configuration or open slots. Unlike unauditedPathPrefixes, they
+// don't cover their sub-paths, e.g. /patients/customfields/:id:/:value: is a
+// patient search.
+var unauditedPaths = []string{
+	"/appointments/customfields",
+	"/appointments/open",
+	"/patients/customfields",
+}
+
+// patientIDKeys are the query and form keys whose values are patient IDs.
+var patientIDKeys = []string{
+	"patientid",
+}
+
+type auditActorContextKey struct{}
+
+type auditActor struct {
+	actor   string
+	purpose string
+}
+
+// ContextWithAuditActor returns a copy of ctx that attributes the calls made
+// with it to actor (e.g. a user or service ID) for purpose (e.g. treatment)
+// in audit events.
+func ContextWithAuditActor(ctx context.Context, actor, purpose string) context.Context {
+	return context.WithValue(ctx, auditActorContextKey{}, auditActor{actor: actor, purpose: purpose})
+}
+
+// AuditActorFromContext returns the actor and purpose set by
+// ContextWithAuditActor.
+func AuditActorFromContext(ctx context.Context) (actor, purpose string) {
+	a, _ := ctx.Value(auditActorContextKey{}).(auditActor)
+
+	return a.actor, a.purpose
+}
+
+type auditFormContextKey struct{}
+
+// WithAuditor configures an Auditor that receives an event for every call that
+// accesses patient data: calls that name a patient in their path (e.g.
+// /patients/1 or /chart/1/problems) or in a patientid query or form value, and
+// calls to patient searches and change feeds. Events are sent after the call
+// completes, including calls that fail and writes skipped in dry-run mode.
+//
+// Auditor errors are logged and don't fail the call.
+func (h *HTTPClient) WithAuditor(auditor Auditor) *HTTPClient {
+	h.auditor = auditor
+
+	return h
+}
+
+// contextWithAuditForm returns a copy of ctx that carries the form of a
+// request so that patient IDs can be read from it.
+func (h *HTTPClient) contextWithAuditForm(ctx context.Context, form url.Values) context.Context {
+	if h.auditor == nil || form == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, auditFormContextKey{}, form)
+}
+
+// audit sends the audit event of a request to path if it accesses patient
+// data.
+func (h *HTTPClient) audit(ctx context.Context, method, path, xRequestID string, dryRun bool, res *http.Response, err error) {
+	if h.auditor == nil {
+		return
+	}
+
+	form, _ := ctx.Value(auditFormContextKey{}).(url.Values)
+
+	patientIDs := auditPatientIDs(path, form)
+	if len(patientIDs) == 0 && !auditedPath(path) {
+		return
+	}
+
+	actor, purpose := AuditActorFromContext(ctx)
+
+	event := &audit.Event{
+		Time:       time.Now().UTC(),
+		PracticeID: h.practiceID,
+		Actor:      actor,
+		Purpose:    purpose,
+		Operation:  auditOperation(ctx),
+		Method:     method,
+		Path:       stats.CleanPath(path),
+		PatientIDs: patientIDs,
+		Outcome:    auditOutcome(err),
+		XRequestID: xRequestID,
+		DryRun:     dryRun,
+	}
+
+	if res != nil {
+		event.StatusCode = res.StatusCode
+	}
+
+	if err != nil {
+		event.Error = h.logRedactor().Error(err).Error()
+	}
+
+	auditErr := h.auditor.Audit(context.WithoutCancel(ctx), event)
+	if auditErr != nil {
+		h.logger.Warn().
+			Err(auditErr).
+			Str("operation", event.Operation).
+			Str("xRequestId", xRequestID).
+			Msg("athenahealth auditor error")
+	}
+}
+
+func auditOutcome(err error) audit.Outcome {
+	switch {
+	case err == nil:
+		return audit.OutcomeSuccess
+
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden):
+		return audit.OutcomeDenied
+	}
+
+	return audit.OutcomeFailure
+}
+
+// auditedPath reports whether requests to path access patient data whether or
+// not they name a patient.
+func auditedPath(path string) bool {
+	cleanPath := stats.CleanPath(path)
+
+	for _, prefix := range unauditedPathPrefixes {
+		if strings.HasPrefix(cleanPath, prefix) {
+			return false
+		}
+	}
+
+	if slices.Contains(unauditedPaths, strings.TrimSuffix(cleanPath, "/")) {
+		return false
+	}
+
+	for _, prefix := range auditedPathPrefixes {
+		if cleanPath == prefix || strings.HasPrefix(cleanPath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditPatientIDs returns the patient IDs in the path, query and form of a
+// request, without duplicates.
+func auditPatientIDs(path string, form url.Values) []string {
+	var ids []string
+
+	add := func(values ...string) {
+		for _, value := range values {
+			for _, id := range strings.Split(value, ",") {
+				id = strings.TrimSpace(id)
+				if !isNumericID(id) {
+					continue
+				}
+
+				found := false
+				for _, existing := range ids {
+					if existing == id {
+						found = true
+						break
+					}
+				}
+
+				if !found {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	rawPath, rawQuery, _ := strings.Cut(path, "?")
+
+	// Patient IDs follow /patients and /chart, e.g. /patients/1/insurances and
+	// /chart/1/problems. Other segments follow them too, e.g.
+	// /patients/changed and /chart/encounter/1, but aren't numeric.
+	segments := strings.Split(strings.Trim(rawPath, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "patients" || segments[i] == "chart" {
+			add(segments[i+1])
+		}
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err == nil {
+		for _, key := range patientIDKeys {
+			add(query[key]...)
+		}
+	}
+
+	for _, key := range patientIDKeys {
+		add(form[key]...)
+	}
+
+	return ids
+}
+
+func isNumericID(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type auditOperationContextKey struct{}
+
+// contextWithAuditOperation returns a copy of ctx that names the operation of
+// the requests made with it in audit events, e.g. GetPatient. Every exported
+// method that calls athena names itself. An operation already named by ctx is
+// kept, so requests are attributed to the method the caller called, e.g.
+// ListPatientsBulk rather than the ListPatients calls it makes.
+func contextWithAuditOperation(ctx context.Context, operation string) context.Context {
+	if _, ok := ctx.Value(auditOperationContextKey{}).(string); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, auditOperationContextKey{}, operation)
+}
+
+// auditOperation returns the operation named by ctx. It is empty for requests
+// made directly with e.g. Get.
+func auditOperation(ctx context.Context) string {
+	operation, _ := ctx.Value(auditOperationContextKey{}).(string)
+
+	return operation
+}
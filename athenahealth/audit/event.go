@@ -0,0 +1,47 @@
+package audit
+
+import "time"
+
+// Outcome is the outcome of an audited call.
+type Outcome string
+
+const (
+	// OutcomeSuccess means athena returned the requested data or performed
+	// the requested write.
+	OutcomeSuccess Outcome = "success"
+	// OutcomeDenied means athena refused access (401 or 403).
+	OutcomeDenied Outcome = "denied"
+	// OutcomeFailure means the call failed for any other reason. It may not
+	// have reached athena.
+	OutcomeFailure Outcome = "failure"
+)
+
+// Event records a call that accessed patient data.
+type Event struct {
+	Time       time.Time `json:"time"`
+	PracticeID string    `json:"practice_id"`
+
+	// Actor and Purpose are who made the call and why, as set with
+	// athenahealth.ContextWithAuditActor.
+	Actor   string `json:"actor"`
+	Purpose string `json:"purpose"`
+
+	// Operation is the client method that made the call, e.g. GetPatient.
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+	// Path is the templated path of the request, e.g. /patients/:id:.
+	Path string `json:"path"`
+	// PatientIDs are the patients named in the request's path, query or form.
+	// It is empty for searches and feeds that don't name a patient.
+	PatientIDs []string `json:"patient_ids"`
+
+	Outcome    Outcome `json:"outcome"`
+	StatusCode int     `json:"status_code,omitempty"`
+	// Error is the redacted error of failed calls.
+	Error string `json:"error,omitempty"`
+
+	XRequestID string `json:"x_request_id"`
+	// DryRun is true for writes that were not sent because the client is in
+	// dry-run mode.
+	DryRun bool `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// JSONLines writes events to a writer as JSON, one event per line.
+type JSONLines struct {
+	w io.Writer
+	c io.Closer
+
+	lock sync.Mutex
+}
+
+// NewJSONLines returns a JSONLines that writes to w.
+func NewJSONLines(w io.Writer) *JSONLines {
+	return &JSONLines{
+		w: w,
+	}
+}
+
+// OpenFile returns a JSONLines that appends to the file at path, creating it
+// with mode 0600 if it doesn't exist. Close the JSONLines to close the file.
+func OpenFile(path string) (*JSONLines, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONLines{
+		w: f,
+		c: f,
+	}, nil
+}
+
+func (j *JSONLines) Audit(ctx context.Context, event *Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	// A single write keeps lines whole when several processes append to the
+	// same file.
+	_, err = j.w.Write(b)
+
+	return err
+}
+
+// Close closes the file opened by OpenFile. It does nothing for JSONLines
+// created with NewJSONLines.
+func (j *JSONLines) Close() error {
+	if j.c == nil {
+		return nil
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.c.Close()
+}
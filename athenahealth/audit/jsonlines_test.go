@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLines(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	j, err := OpenFile(path)
+	assert.NoError(err)
+
+	event := &Event{
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		PracticeID: "195900",
+		Actor:      "user-1",
+		Purpose:    "treatment",
+		Operation:  "GetPatient",
+		Method:     "GET",
+		Path:       "/patients/:id:",
+		PatientIDs: []string{"1"},
+		Outcome:    OutcomeSuccess,
+		StatusCode: 200,
+		XRequestID: "abc",
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(j.Audit(context.Background(), event))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.NoError(j.Close())
+
+	f, err := os.Open(path)
+	assert.NoError(err)
+	defer f.Close()
+
+	lines := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+
+		decoded := &Event{}
+		assert.NoError(json.Unmarshal(scanner.Bytes(), decoded))
+		assert.Equal(event, decoded)
+	}
+
+	assert.NoError(scanner.Err())
+	assert.Equal(10, lines)
+
+	info, err := os.Stat(path)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestJSONLines_appends(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		j, err := OpenFile(path)
+		assert.NoError(err)
+
+		assert.NoError(j.Audit(context.Background(), &Event{Operation: "GetPatient"}))
+		assert.NoError(j.Close())
+	}
+
+	b, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(2, strings.Count(string(b), "\n"))
+}
@@ -0,0 +1,303 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/audit"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuditor struct {
+	events []*audit.Event
+	err    error
+
+	lock sync.Mutex
+}
+
+func (t *testAuditor) Audit(ctx context.Context, event *audit.Event) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.events = append(t.events, event)
+
+	return t.err
+}
+
+func TestHTTPClient_WithAuditor(t *testing.T) {
+	assert := assert.New(t)
+
+	var xRequestIDs []string
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		xRequestIDs = append(xRequestIDs, r.Header.Get(XRequestIDHeaderKey))
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/labresults"):
+			w.Write([]byte(`{"labresults":[]}`))
+
+		case strings.HasSuffix(r.URL.Path, "/departments"):
+			w.Write([]byte(`{"departments":[]}`))
+
+		case strings.HasPrefix(r.URL.Path, "/appointments/"):
+			w.Write([]byte(`[{"appointmentid":"2","patientid":"1"}]`))
+
+		default:
+			w.Write([]byte(`[{"patientid":"1"}]`))
+		}
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	auditor := &testAuditor{}
+	athenaClient.
+		WithAuditor(auditor).
+		WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithAuditActor(context.Background(), "user-1", "treatment")
+
+	_, err := athenaClient.GetPatient(ctx, "1", nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.ListLabResults(ctx, "2", "3", nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.BookAppointment(ctx, "4", "5", nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.CreatePatient(ContextWithIdempotencyKey(ctx, "key"), &CreatePatientOptions{})
+	assert.NoError(err)
+
+	// Departments are not patient data.
+	_, err = athenaClient.ListDepartments(ctx, nil)
+	assert.NoError(err)
+
+	if !assert.Len(auditor.events, 4) {
+		return
+	}
+
+	event := auditor.events[0]
+	assert.WithinDuration(time.Now(), event.Time, time.Minute)
+	assert.Equal(testPracticeID, event.PracticeID)
+	assert.Equal("user-1", event.Actor)
+	assert.Equal("treatment", event.Purpose)
+	assert.Equal("GetPatient", event.Operation)
+	assert.Equal(http.MethodGet, event.Method)
+	assert.Equal("/patients/:id:", event.Path)
+	assert.Equal([]string{"1"}, event.PatientIDs)
+	assert.Equal(audit.OutcomeSuccess, event.Outcome)
+	assert.Equal(http.StatusOK, event.StatusCode)
+	assert.Empty(event.Error)
+	assert.Equal(xRequestIDs[0], event.XRequestID)
+
+	assert.Equal("ListLabResults", auditor.events[1].Operation)
+	assert.Equal("/chart/:id:/labresults", auditor.events[1].Path)
+	assert.Equal([]string{"2"}, auditor.events[1].PatientIDs)
+
+	assert.Equal("BookAppointment", auditor.events[2].Operation)
+	assert.Equal([]string{"4"}, auditor.events[2].PatientIDs)
+
+	assert.Equal("CreatePatient", auditor.events[3].Operation)
+	assert.Equal(http.MethodPost, auditor.events[3].Method)
+	assert.Empty(auditor.events[3].PatientIDs)
+}
+
+func TestHTTPClient_WithAuditor_failure(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"Forbidden"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	auditor := &testAuditor{err: errors.New("audit sink down")}
+	athenaClient.WithAuditor(auditor)
+
+	_, err := athenaClient.GetPatient(context.Background(), "1", nil)
+	assert.ErrorIs(err, ErrForbidden)
+
+	if !assert.Len(auditor.events, 1) {
+		return
+	}
+
+	assert.Equal(audit.OutcomeDenied, auditor.events[0].Outcome)
+	assert.Equal(http.StatusForbidden, auditor.events[0].StatusCode)
+	assert.NotEmpty(auditor.events[0].Error)
+	assert.Empty(auditor.events[0].Actor)
+}
+
+func TestHTTPClient_WithAuditor_dryRun(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testClient(nil)
+	defer ts.Close()
+
+	auditor := &testAuditor{}
+	athenaClient.
+		WithAuditor(auditor).
+		WithDryRun(true)
+
+	_, err := athenaClient.AddDocumentReader(context.Background(), "1", &AddDocumentReaderOptions{
+		AttachmentContents: strings.NewReader("hello"),
+	})
+	assert.NoError(err)
+
+	if !assert.Len(auditor.events, 1) {
+		return
+	}
+
+	assert.Equal("AddDocumentReader", auditor.events[0].Operation)
+	assert.Equal([]string{"1"}, auditor.events[0].PatientIDs)
+	assert.True(auditor.events[0].DryRun)
+}
+
+func TestAuditPatientIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"1"}, auditPatientIDs("/patients/1/insurances/2", nil))
+	assert.Equal([]string{"1"}, auditPatientIDs("/chart/1/problems", nil))
+	assert.Equal([]string{"1", "2"}, auditPatientIDs("/patients/1,2", nil))
+	assert.Equal([]string{"3", "4"}, auditPatientIDs("/appointments/booked?patientid=3&departmentid=9", url.Values{"patientid": {"4", "3"}}))
+	assert.Empty(auditPatientIDs("/chart/encounter/1/physicalexam", nil))
+	assert.Empty(auditPatientIDs("/patients/changed", nil))
+	assert.Empty(auditPatientIDs("/departments/1", nil))
+}
+
+func TestAuditedPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(auditedPath("/patients?firstname=John"))
+	assert.True(auditedPath("/patients/changed"))
+	assert.True(auditedPath("/labresults/changed"))
+	assert.True(auditedPath("/chart/encounters/1/summary"))
+	assert.False(auditedPath("/chart/configuration/socialhistory"))
+	assert.False(auditedPath("/patients/customfields"))
+	assert.False(auditedPath("/patients/customfields/"))
+	assert.True(auditedPath("/patients/customfields/1/value"))
+	assert.True(auditedPath("/appointments/1"))
+	assert.True(auditedPath("/appointments/booked"))
+	assert.True(auditedPath("/appointments/1/notes"))
+	assert.True(auditedPath("/appointments/1/healthhistoryforms/2"))
+	assert.False(auditedPath("/appointments/customfields"))
+	assert.False(auditedPath("/appointments/open"))
+	assert.True(auditedPath("/claims"))
+	assert.False(auditedPath("/claimsx"))
+	assert.False(auditedPath("/patientsx"))
+	assert.False(auditedPath("/departments"))
+}
+
+func TestHTTPClient_WithAuditor_searches(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/claims"):
+			w.Write([]byte(`{"claims":[]}`))
+
+		case strings.HasPrefix(r.URL.Path, "/appointments/"):
+			w.Write([]byte(`[{"appointmentid":"2"}]`))
+
+		default:
+			w.Write([]byte(`{"patients":[]}`))
+		}
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	auditor := &testAuditor{}
+	athenaClient.WithAuditor(auditor)
+
+	ctx := context.Background()
+
+	_, err := athenaClient.ListPatientsMatchingCustomField(ctx, &ListPatientsMatchingCustomFieldOptions{
+		CustomFieldID:    "1",
+		CustomFieldValue: "value",
+	})
+	assert.NoError(err)
+
+	_, err = athenaClient.GetAppointment(ctx, "2")
+	assert.NoError(err)
+
+	_, err = athenaClient.ListClaims(ctx, &ListClaimsOptions{})
+	assert.NoError(err)
+
+	if assert.Len(auditor.events, 3) {
+		assert.Equal("ListPatientsMatchingCustomField", auditor.events[0].Operation)
+		assert.Equal("GetAppointment", auditor.events[1].Operation)
+		assert.Equal("ListClaims", auditor.events[2].Operation)
+	}
+}
+
+func TestHTTPClient_WithAuditor_pager(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Query().Get("offset")) == 0 {
+			fmt.Fprintf(w, `{"patients":[{"patientid":"1"}],"next":"/%s/patients?offset=1","totalcount":2}`, testPracticeID)
+
+			return
+		}
+
+		w.Write([]byte(`{"patients":[{"patientid":"2"}],"totalcount":2}`))
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	auditor := &testAuditor{}
+	athenaClient.WithAuditor(auditor)
+
+	patients, err := athenaClient.ListPatientsPager(&ListPatientsOptions{}).CollectAll(context.Background(), 0)
+	assert.NoError(err)
+	assert.Len(patients, 2)
+
+	// Later pages are fetched outside ListPatientsPager, but are audited as
+	// part of it too.
+	if assert.Len(auditor.events, 2) {
+		assert.Equal("ListPatientsPager", auditor.events[0].Operation)
+		assert.Equal("ListPatientsPager", auditor.events[1].Operation)
+	}
+}
+
+func TestHTTPClient_WithAuditor_operation(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"patients":[{"patientid":"1"}],"totalcount":1}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	auditor := &testAuditor{}
+	athenaClient.WithAuditor(auditor)
+
+	ctx := context.Background()
+
+	// The method the caller called names the operation, not the methods it
+	// calls.
+	_, err := athenaClient.ListPatientsBulk(ctx, &ListPatientsOptions{}, nil)
+	assert.NoError(err)
+
+	// Requests made directly have no operation.
+	_, err = athenaClient.Get(ctx, "/patients", nil, nil)
+	assert.NoError(err)
+
+	if assert.Len(auditor.events, 2) {
+		assert.Equal("ListPatientsBulk", auditor.events[0].Operation)
+		assert.Empty(auditor.events[1].Operation)
+	}
+}
@@ -0,0 +1,244 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+)
+
+// ErrBulkheadFull is matched by errors returned when a request waited too long
+// for a free slot in its endpoint group's bulkhead.
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// BulkheadFullError is returned, wrapped in an *APIError, without sending the
+// request when no slot in its endpoint group's bulkhead became free within the
+// queue timeout.
+type BulkheadFullError struct {
+	Group  EndpointGroup
+	Waited time.Duration
+}
+
+func (e *BulkheadFullError) Error() string {
+	return fmt.Sprintf("athenahealth bulkhead for %s full, waited %s", e.Group, e.Waited)
+}
+
+func (e *BulkheadFullError) Is(target error) bool {
+	return target == ErrBulkheadFull
+}
+
+// BulkheadStatsRecorder is implemented by StatsRecorders that record bulkhead
+// queueing.
+type BulkheadStatsRecorder interface {
+	RecordBulkheadAcquire(stats.BulkheadAcquire) error
+	RecordBulkheadRelease(stats.BulkheadRelease) error
+}
+
+// BulkheadLimit limits the concurrency of requests to an endpoint group.
+type BulkheadLimit struct {
+	// MaxConcurrent is the number of requests to the group that can be in
+	// flight at once.
+	MaxConcurrent int
+	// QueueTimeout is how long a request waits for a slot before failing with
+	// a *BulkheadFullError. Zero waits as long as the request's context allows.
+	QueueTimeout time.Duration
+}
+
+// Bulkhead caps the number of concurrent requests per endpoint group so that a
+// busy group, e.g. a batch of document uploads, can't starve the others of
+// connections and rate limit budget. Requests to groups without a limit are
+// not capped.
+//
+// Slots are held while a request is sent and its response read, but not while
+// it waits to be retried. A Bulkhead is shared by the clients returned by
+// ForPractice.
+type Bulkhead struct {
+	compartments map[EndpointGroup]*compartment
+}
+
+type compartment struct {
+	limit  BulkheadLimit
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+// NewBulkhead returns a Bulkhead enforcing limits. Limits with a MaxConcurrent
+// of zero or less are ignored.
+func NewBulkhead(limits map[EndpointGroup]BulkheadLimit) *Bulkhead {
+	b := &Bulkhead{
+		compartments: map[EndpointGroup]*compartment{},
+	}
+
+	for group, limit := range limits {
+		if limit.MaxConcurrent <= 0 {
+			continue
+		}
+
+		b.compartments[group] = &compartment{
+			limit: limit,
+			slots: make(chan struct{}, limit.MaxConcurrent),
+		}
+	}
+
+	return b
+}
+
+// InFlight returns the number of requests to group holding a slot.
+func (b *Bulkhead) InFlight(group EndpointGroup) int {
+	c, ok := b.compartments[group]
+	if !ok {
+		return 0
+	}
+
+	return len(c.slots)
+}
+
+// Queued returns the number of requests to group waiting for a slot.
+func (b *Bulkhead) Queued(group EndpointGroup) int {
+	c, ok := b.compartments[group]
+	if !ok {
+		return 0
+	}
+
+	return int(c.queued.Load())
+}
+
+// acquire waits for a slot in group's compartment. It returns a nil
+// compartment if group is not limited.
+func (b *Bulkhead) acquire(ctx context.Context, group EndpointGroup) (*compartment, time.Duration, error) {
+	c, ok := b.compartments[group]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c, 0, nil
+
+	default:
+	}
+
+	c.queued.Add(1)
+	defer c.queued.Add(-1)
+
+	start := time.Now()
+
+	var timeout <-chan time.Time
+	if c.limit.QueueTimeout > 0 {
+		timer := time.NewTimer(c.limit.QueueTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c, time.Since(start), nil
+
+	case <-timeout:
+		waited := time.Since(start)
+
+		return nil, waited, &BulkheadFullError{Group: group, Waited: waited}
+
+	case <-ctx.Done():
+		return nil, time.Since(start), ctx.Err()
+	}
+}
+
+func (c *compartment) release() {
+	<-c.slots
+}
+
+// WithBulkhead configures per endpoint group concurrency limits. Requests are
+// grouped by the EndpointGrouper.
+func (h *HTTPClient) WithBulkhead(bulkhead *Bulkhead) *HTTPClient {
+	h.bulkhead = bulkhead
+
+	return h
+}
+
+func (h *HTTPClient) bulkheadMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if h.bulkhead == nil {
+			return next.Do(req)
+		}
+
+		info := requestInfoFromRequest(req)
+		group := h.endpointGrouper(req.Method, info.path)
+
+		c, wait, err := h.bulkhead.acquire(req.Context(), group)
+		if c == nil && err == nil {
+			return next.Do(req)
+		}
+
+		h.recordBulkheadAcquire(group, wait, err != nil)
+
+		if err != nil {
+			h.logger.Warn().
+				Str("group", string(group)).
+				Str("wait", wait.String()).
+				Str("xRequestId", info.xRequestID).
+				Err(err).
+				Msg("athenahealth bulkhead rejected request")
+
+			return nil, err
+		}
+
+		release := func() {
+			c.release()
+			h.recordBulkheadRelease(group)
+		}
+
+		res, err := next.Do(req)
+		if res == nil || res.Body == nil {
+			release()
+
+			return res, err
+		}
+
+		// Hold the slot until the response body has been read.
+		onBodyClose(res, func(int64) {
+			release()
+		})
+
+		return res, err
+	})
+}
+
+func (h *HTTPClient) recordBulkheadAcquire(group EndpointGroup, wait time.Duration, rejected bool) {
+	recorder, ok := h.stats.(BulkheadStatsRecorder)
+	if !ok {
+		return
+	}
+
+	err := recorder.RecordBulkheadAcquire(stats.BulkheadAcquire{
+		Group:    string(group),
+		Wait:     wait,
+		Rejected: rejected,
+		InFlight: h.bulkhead.InFlight(group),
+		Queued:   h.bulkhead.Queued(group),
+	})
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("athenahealth stats error")
+	}
+}
+
+func (h *HTTPClient) recordBulkheadRelease(group EndpointGroup) {
+	recorder, ok := h.stats.(BulkheadStatsRecorder)
+	if !ok {
+		return
+	}
+
+	err := recorder.RecordBulkheadRelease(stats.BulkheadRelease{
+		Group:    string(group),
+		InFlight: h.bulkhead.InFlight(group),
+		Queued:   h.bulkhead.Queued(group),
+	})
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("athenahealth stats error")
+	}
+}
@@ -0,0 +1,183 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBulkheadStatsRecorder struct {
+	*stats.Default
+
+	acquires []stats.BulkheadAcquire
+	releases []stats.BulkheadRelease
+
+	lock sync.Mutex
+}
+
+func (t *testBulkheadStatsRecorder) RecordBulkheadAcquire(acquire stats.BulkheadAcquire) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.acquires = append(t.acquires, acquire)
+
+	return nil
+}
+
+func (t *testBulkheadStatsRecorder) RecordBulkheadRelease(release stats.BulkheadRelease) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.releases = append(t.releases, release)
+
+	return nil
+}
+
+func TestHTTPClient_WithBulkhead(t *testing.T) {
+	assert := assert.New(t)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/documents") {
+			started <- struct{}{}
+			<-unblock
+		}
+
+		w.Write([]byte(`{}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	bulkhead := NewBulkhead(map[EndpointGroup]BulkheadLimit{
+		EndpointGroupDocuments: {MaxConcurrent: 1, QueueTimeout: 20 * time.Millisecond},
+	})
+
+	recorder := &testBulkheadStatsRecorder{Default: stats.NewDefault()}
+
+	athenaClient.WithBulkhead(bulkhead).WithStatsRecorder(recorder)
+
+	done := make(chan error)
+
+	go func() {
+		_, err := athenaClient.Get(context.Background(), "/patients/1/documents", nil, nil)
+		done <- err
+	}()
+
+	<-started
+
+	assert.Equal(1, bulkhead.InFlight(EndpointGroupDocuments))
+
+	// The documents group is full.
+	_, err := athenaClient.Get(context.Background(), "/patients/2/documents", nil, nil)
+	assert.ErrorIs(err, ErrBulkheadFull)
+
+	bulkheadErr := &BulkheadFullError{}
+	if assert.ErrorAs(err, &bulkheadErr) {
+		assert.Equal(EndpointGroupDocuments, bulkheadErr.Group)
+		assert.GreaterOrEqual(bulkheadErr.Waited, 20*time.Millisecond)
+	}
+
+	// Other groups are not affected.
+	_, err = athenaClient.Get(context.Background(), "/appointments/open", nil, nil)
+	assert.NoError(err)
+
+	close(unblock)
+	assert.NoError(<-done)
+
+	assert.Equal(0, bulkhead.InFlight(EndpointGroupDocuments))
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	if assert.Len(recorder.acquires, 2) {
+		assert.Equal(stats.BulkheadAcquire{Group: "documents", InFlight: 1}, recorder.acquires[0])
+
+		assert.Equal("documents", recorder.acquires[1].Group)
+		assert.True(recorder.acquires[1].Rejected)
+		assert.Equal(1, recorder.acquires[1].InFlight)
+	}
+
+	assert.Equal([]stats.BulkheadRelease{{Group: "documents"}}, recorder.releases)
+}
+
+func TestHTTPClient_WithBulkhead_queue(t *testing.T) {
+	assert := assert.New(t)
+
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+
+		w.Write([]byte(`{}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithBulkhead(NewBulkhead(map[EndpointGroup]BulkheadLimit{
+		EndpointGroupScheduling: {MaxConcurrent: 2},
+	}))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := athenaClient.Get(context.Background(), "/appointments/open", nil, nil)
+			assert.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(2, maxInFlight)
+}
+
+func TestBulkhead_acquire_contextDone(t *testing.T) {
+	assert := assert.New(t)
+
+	bulkhead := NewBulkhead(map[EndpointGroup]BulkheadLimit{
+		EndpointGroupChart:    {MaxConcurrent: 1},
+		EndpointGroupPatients: {MaxConcurrent: 0},
+	})
+
+	c, _, err := bulkhead.acquire(context.Background(), EndpointGroupChart)
+	assert.NoError(err)
+	assert.NotNil(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = bulkhead.acquire(ctx, EndpointGroupChart)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(0, bulkhead.Queued(EndpointGroupChart))
+
+	c.release()
+
+	// Groups without a limit are not capped.
+	c, _, err = bulkhead.acquire(context.Background(), EndpointGroupPatients)
+	assert.NoError(err)
+	assert.Nil(c)
+}
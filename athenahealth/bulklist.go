@@ -0,0 +1,142 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBulkListWorkers is the number of pages fetched at once by the
+// ListXBulk methods when BulkListOptions.Workers is not set.
+const DefaultBulkListWorkers = 4
+
+// BulkListOptions configures the ListXBulk methods.
+type BulkListOptions struct {
+	// Workers is the number of pages fetched at once. Requests are still
+	// subject to the client's RateLimiter.
+	Workers int
+}
+
+// BulkListError is returned by the ListXBulk methods when some pages can't be
+// fetched. The items of the other pages are still returned.
+type BulkListError struct {
+	// Errs maps the offset of each page that failed to its error.
+	Errs map[int]error
+}
+
+func (e *BulkListError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "athenahealth: fetching %d pages failed:", len(e.Errs))
+
+	for _, offset := range e.Offsets() {
+		fmt.Fprintf(&b, " offset %d: %s;", offset, e.Errs[offset])
+	}
+
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func (e *BulkListError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errs))
+
+	for _, offset := range e.Offsets() {
+		errs = append(errs, e.Errs[offset])
+	}
+
+	return errs
+}
+
+// Offsets returns the offsets of the pages that failed in ascending order.
+func (e *BulkListError) Offsets() []int {
+	offsets := make([]int, 0, len(e.Errs))
+
+	for offset := range e.Errs {
+		offsets = append(offsets, offset)
+	}
+
+	sort.Ints(offsets)
+
+	return offsets
+}
+
+// bulkList fetches the first page of a list with fetch, then the pages after
+// it concurrently once the total count is known. Pages are as long as the
+// first page unless pagination sets a limit. Items are returned in list order.
+func bulkList[T any](ctx context.Context, pagination *PaginationOptions, opts *BulkListOptions, fetch func(context.Context, *PaginationOptions) ([]T, *PaginationResult, error)) ([]T, error) {
+	workers := DefaultBulkListWorkers
+	if opts != nil && opts.Workers > 0 {
+		workers = opts.Workers
+	}
+
+	first := &PaginationOptions{}
+	if pagination != nil {
+		*first = *pagination
+	}
+
+	items, res, err := fetch(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := first.Limit
+	if limit <= 0 {
+		limit = len(items)
+	}
+
+	if limit == 0 || res == nil || len(res.Next) == 0 {
+		return items, nil
+	}
+
+	var offsets []int
+	for offset := first.Offset + limit; offset < res.TotalCount; offset += limit {
+		offsets = append(offsets, offset)
+	}
+
+	pages := make([][]T, len(offsets))
+	errs := map[int]error{}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	indexes := make(chan int)
+
+	for i := 0; i < min(workers, len(offsets)); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				err := ctx.Err()
+				if err == nil {
+					pages[i], _, err = fetch(ctx, &PaginationOptions{Limit: limit, Offset: offsets[i]})
+				}
+
+				if err != nil {
+					lock.Lock()
+					errs[offsets[i]] = err
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range offsets {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	for _, page := range pages {
+		items = append(items, page...)
+	}
+
+	if len(errs) > 0 {
+		return items, &BulkListError{Errs: errs}
+	}
+
+	return items, nil
+}
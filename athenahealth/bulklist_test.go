@@ -0,0 +1,146 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPaginatedClaims(totalCount int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		if limit == 0 {
+			limit = 3
+		}
+
+		claims := ""
+		for i := offset; i < min(offset+limit, totalCount); i++ {
+			if len(claims) > 0 {
+				claims += ","
+			}
+
+			claims += fmt.Sprintf(`{"claimid":"%d"}`, i+1)
+		}
+
+		next := ""
+		if offset+limit < totalCount {
+			next = fmt.Sprintf(`"next":"/%s/claims?limit=%d&offset=%d",`, testPracticeID, limit, offset+limit)
+		}
+
+		fmt.Fprintf(w, `{"claims":[%s],%s"totalcount":%d}`, claims, next, totalCount)
+	}
+}
+
+func testClaimIDs(claims []*Claim) []string {
+	var ids []string
+
+	for _, claim := range claims {
+		ids = append(ids, claim.ClaimID)
+	}
+
+	return ids
+}
+
+func TestHTTPClient_ListClaimsBulk(t *testing.T) {
+	assert := assert.New(t)
+
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+
+		testPaginatedClaims(10)(w, r)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	var allowed atomic.Int32
+
+	athenaClient.WithRateLimiter(&testRateLimiter{
+		AllowedFunc: func(bool) (time.Duration, error) {
+			allowed.Add(1)
+
+			return 0, nil
+		},
+	})
+
+	claims, err := athenaClient.ListClaimsBulk(context.Background(), &ListClaimsOptions{
+		Pagination: &PaginationOptions{Limit: 2},
+	}, &BulkListOptions{Workers: 2})
+	assert.NoError(err)
+	assert.Equal([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, testClaimIDs(claims))
+
+	assert.Equal(2, maxInFlight)
+	assert.Equal(int32(5), allowed.Load())
+}
+
+func TestHTTPClient_ListClaimsBulk_pageSize(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int32
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		testPaginatedClaims(7)(w, r)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	// Without a limit, pages are as long as athena's first page.
+	claims, err := athenaClient.ListClaimsBulk(context.Background(), nil, nil)
+	assert.NoError(err)
+	assert.Equal([]string{"1", "2", "3", "4", "5", "6", "7"}, testClaimIDs(claims))
+	assert.Equal(int32(3), requests.Load())
+}
+
+func TestHTTPClient_ListClaimsBulk_partialFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		offset := r.URL.Query().Get("offset")
+		if offset == "4" || offset == "8" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+
+			return
+		}
+
+		testPaginatedClaims(10)(w, r)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	claims, err := athenaClient.ListClaimsBulk(context.Background(), &ListClaimsOptions{
+		Pagination: &PaginationOptions{Limit: 2},
+	}, nil)
+	assert.ErrorIs(err, ErrNotFound)
+	assert.Equal([]string{"1", "2", "3", "4", "7", "8"}, testClaimIDs(claims))
+
+	bulkErr := &BulkListError{}
+	if assert.ErrorAs(err, &bulkErr) {
+		assert.Equal([]int{4, 8}, bulkErr.Offsets())
+	}
+}
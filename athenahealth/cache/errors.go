@@ -0,0 +1,6 @@
+package cache
+
+import "errors"
+
+// ErrNotFound is returned by Get for keys that are not cached or have expired.
+var ErrNotFound = errors.New("cache entry not found")
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRUDefaultSize is the number of entries kept by an LRU created with a size
+// of zero.
+const LRUDefaultSize = 1000
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// LRU caches entries in memory, evicting the least recently used entry once
+// it holds size entries.
+type LRU struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+
+	lock sync.Mutex
+}
+
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = LRUDefaultSize
+	}
+
+	return &LRU{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (l *LRU) Get(ctx context.Context, key string) ([]byte, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entry := el.Value.(*lruEntry)
+	if !l.now().Before(entry.expiresAt) {
+		l.remove(el)
+
+		return nil, ErrNotFound
+	}
+
+	l.order.MoveToFront(el)
+
+	return entry.value, nil
+}
+
+func (l *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	expiresAt := l.now().Add(ttl)
+
+	if el, ok := l.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+
+		l.order.MoveToFront(el)
+
+		return nil
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+func (l *LRU) DeletePrefix(ctx context.Context, prefix string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for key, el := range l.entries {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(el)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// that have not been evicted yet.
+func (l *LRU) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *LRU) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.entries, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+
+	l := NewLRU(2)
+	l.now = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+
+	_, err := l.Get(ctx, "a")
+	assert.ErrorIs(err, ErrNotFound)
+
+	assert.NoError(l.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(l.Set(ctx, "b", []byte("2"), time.Hour))
+
+	// a is now more recently used than b.
+	val, err := l.Get(ctx, "a")
+	assert.NoError(err)
+	assert.Equal([]byte("1"), val)
+
+	assert.NoError(l.Set(ctx, "c", []byte("3"), time.Hour))
+	assert.Equal(2, l.Len())
+
+	_, err = l.Get(ctx, "b")
+	assert.ErrorIs(err, ErrNotFound)
+
+	now = now.Add(2 * time.Minute)
+
+	_, err = l.Get(ctx, "a")
+	assert.ErrorIs(err, ErrNotFound)
+
+	val, err = l.Get(ctx, "c")
+	assert.NoError(err)
+	assert.Equal([]byte("3"), val)
+}
+
+func TestLRU_DeletePrefix(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLRU(0)
+
+	ctx := context.Background()
+
+	assert.NoError(l.Set(ctx, "1:ListDepartments:a", []byte("1"), time.Hour))
+	assert.NoError(l.Set(ctx, "1:ListDepartments:b", []byte("2"), time.Hour))
+	assert.NoError(l.Set(ctx, "1:ListProviders:a", []byte("3"), time.Hour))
+
+	assert.NoError(l.DeletePrefix(ctx, "1:ListDepartments:"))
+
+	_, err := l.Get(ctx, "1:ListDepartments:a")
+	assert.ErrorIs(err, ErrNotFound)
+
+	_, err = l.Get(ctx, "1:ListProviders:a")
+	assert.NoError(err)
+	assert.Equal(1, l.Len())
+}
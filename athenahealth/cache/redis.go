@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisDefaultPrefix is prepended to keys by a Redis created with an empty
+// prefix.
+const RedisDefaultPrefix = "athena_cache:"
+
+// redisScanCount is the number of keys requested per SCAN by DeletePrefix.
+const redisScanCount = 100
+
+// Redis caches entries in Redis so that they are shared between processes.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	if client == nil {
+		panic("client is nil")
+	}
+
+	r := &Redis{
+		client: client,
+		prefix: prefix,
+	}
+
+	if len(r.prefix) == 0 {
+		r.prefix = RedisDefaultPrefix
+	}
+
+	return r
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// DeletePrefix deletes the keys starting with prefix. It scans the keyspace, so
+// it is meant for occasional invalidation rather than every request.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, escapeRedisPattern(r.prefix+prefix)+"*", redisScanCount).Iterator()
+
+	var keys []string
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	err := iter.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// escapeRedisPattern escapes the characters that are special in SCAN MATCH
+// patterns.
+func escapeRedisPattern(s string) string {
+	escaped := make([]byte, 0, len(s))
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			escaped = append(escaped, '\\')
+		}
+
+		escaped = append(escaped, s[i])
+	}
+
+	return string(escaped)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	r := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), "")
+
+	ctx := context.Background()
+
+	_, err = r.Get(ctx, "1:ListDepartments:a")
+	assert.ErrorIs(err, ErrNotFound)
+
+	assert.NoError(r.Set(ctx, "1:ListDepartments:a", []byte("1"), time.Minute))
+	assert.NoError(r.Set(ctx, "1:ListDepartments:b", []byte("2"), time.Minute))
+	assert.NoError(r.Set(ctx, "1:ListProviders:[a]", []byte("3"), time.Minute))
+
+	val, err := r.Get(ctx, "1:ListDepartments:a")
+	assert.NoError(err)
+	assert.Equal([]byte("1"), val)
+	assert.True(s.Exists(RedisDefaultPrefix + "1:ListDepartments:a"))
+	assert.Equal(time.Minute, s.TTL(RedisDefaultPrefix+"1:ListDepartments:a"))
+
+	assert.NoError(r.DeletePrefix(ctx, "1:ListDepartments:"))
+
+	_, err = r.Get(ctx, "1:ListDepartments:b")
+	assert.ErrorIs(err, ErrNotFound)
+
+	val, err = r.Get(ctx, "1:ListProviders:[a]")
+	assert.NoError(err)
+	assert.Equal([]byte("3"), val)
+
+	assert.NoError(r.DeletePrefix(ctx, "1:ListProviders:["))
+
+	_, err = r.Get(ctx, "1:ListProviders:[a]")
+	assert.ErrorIs(err, ErrNotFound)
+}
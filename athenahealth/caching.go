@@ -0,0 +1,236 @@
+package athenahealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/cache"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultCacheTTL is how long CachingClient caches results of methods without
+// a TTL configured with WithTTL.
+const DefaultCacheTTL = time.Hour
+
+// Cache stores the results cached by CachingClient. See the cache package for
+// implementations.
+type Cache interface {
+	// Get returns the value stored for key, or cache.ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix deletes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// CachingClient is a Client that caches the results of methods returning
+// slow-changing practice configuration: GetDepartment, ListDepartments,
+// GetProvider, ListProviders, ListCustomFields, ListAppointmentCustomFields and
+// ListSocialHistoryTemplates. Other methods are passed through to the wrapped
+// Client.
+//
+// Results are cached per practice and arguments. Concurrent calls for a result
+// that is not cached share a single request to athena. Errors are not cached,
+// and a failing Cache only costs the requests it would have saved.
+type CachingClient struct {
+	Client
+
+	cache  Cache
+	ttls   map[string]time.Duration
+	logger *zerolog.Logger
+
+	flights singleflight.Group
+}
+
+var _ Client = (*CachingClient)(nil)
+
+// NewCachingClient returns a CachingClient that wraps client and caches results
+// in c for DefaultCacheTTL.
+func NewCachingClient(client Client, c Cache) *CachingClient {
+	noplogger := zerolog.Nop()
+
+	return &CachingClient{
+		Client: client,
+
+		cache:  c,
+		ttls:   map[string]time.Duration{},
+		logger: &noplogger,
+	}
+}
+
+// WithTTL configures how long results of method (e.g. "ListDepartments") are
+// cached. A TTL of zero or less disables caching for method.
+func (c *CachingClient) WithTTL(method string, ttl time.Duration) *CachingClient {
+	c.ttls[method] = ttl
+
+	return c
+}
+
+// WithLogger configures the logger that Cache errors are logged to.
+func (c *CachingClient) WithLogger(logger *zerolog.Logger) *CachingClient {
+	c.logger = logger
+
+	return c
+}
+
+// Invalidate removes the cached results of methods for the practice that ctx
+// is routed to, or of every cached method if none are given.
+func (c *CachingClient) Invalidate(ctx context.Context, methods ...string) error {
+	practiceID := c.practiceID(ctx)
+
+	if len(methods) == 0 {
+		return c.cache.DeletePrefix(ctx, practiceID+":")
+	}
+
+	var errs []error
+
+	for _, method := range methods {
+		err := c.cache.DeletePrefix(ctx, fmt.Sprintf("%s:%s:", practiceID, method))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *CachingClient) GetDepartment(ctx context.Context, departmentID string) (*Department, error) {
+	return cached(ctx, c, "GetDepartment", departmentID, func(ctx context.Context) (*Department, error) {
+		return c.Client.GetDepartment(ctx, departmentID)
+	})
+}
+
+func (c *CachingClient) ListDepartments(ctx context.Context, opts *ListDepartmentsOptions) (*ListDepartmentsResult, error) {
+	return cached(ctx, c, "ListDepartments", opts, func(ctx context.Context) (*ListDepartmentsResult, error) {
+		return c.Client.ListDepartments(ctx, opts)
+	})
+}
+
+func (c *CachingClient) GetProvider(ctx context.Context, providerID string) (*Provider, error) {
+	return cached(ctx, c, "GetProvider", providerID, func(ctx context.Context) (*Provider, error) {
+		return c.Client.GetProvider(ctx, providerID)
+	})
+}
+
+func (c *CachingClient) ListProviders(ctx context.Context, opts *ListProvidersOptions) (*ListProvidersResult, error) {
+	return cached(ctx, c, "ListProviders", opts, func(ctx context.Context) (*ListProvidersResult, error) {
+		return c.Client.ListProviders(ctx, opts)
+	})
+}
+
+func (c *CachingClient) ListCustomFields(ctx context.Context) ([]*CustomField, error) {
+	return cached(ctx, c, "ListCustomFields", nil, c.Client.ListCustomFields)
+}
+
+func (c *CachingClient) ListAppointmentCustomFields(ctx context.Context) ([]*AppointmentCustomField, error) {
+	return cached(ctx, c, "ListAppointmentCustomFields", nil, c.Client.ListAppointmentCustomFields)
+}
+
+func (c *CachingClient) ListSocialHistoryTemplates(ctx context.Context) ([]*SocialHistoryTemplate, error) {
+	return cached(ctx, c, "ListSocialHistoryTemplates", nil, c.Client.ListSocialHistoryTemplates)
+}
+
+// practiceID returns the practice that requests made with ctx are sent to.
+func (c *CachingClient) practiceID(ctx context.Context) string {
+	if practiceID, ok := PracticeIDFromContext(ctx); ok {
+		return practiceID
+	}
+
+	if p, ok := c.Client.(interface{ PracticeID() string }); ok {
+		return p.PracticeID()
+	}
+
+	return ""
+}
+
+func (c *CachingClient) ttl(method string) time.Duration {
+	if ttl, ok := c.ttls[method]; ok {
+		return ttl
+	}
+
+	return DefaultCacheTTL
+}
+
+// cached returns the cached result of calling method with args, calling fn to
+// get and cache it if it isn't cached.
+func cached[T any](ctx context.Context, c *CachingClient, method string, args any, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+
+	ttl := c.ttl(method)
+	if ttl <= 0 {
+		return fn(ctx)
+	}
+
+	argsKey, err := json.Marshal(args)
+	if err != nil {
+		return zero, err
+	}
+
+	key := fmt.Sprintf("%s:%s:%s", c.practiceID(ctx), method, argsKey)
+
+	b, err := c.cache.Get(ctx, key)
+	if err == nil {
+		out, err := decodeCached[T](b)
+		if err == nil {
+			return out, nil
+		}
+
+		c.logError(err, method, "decode")
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		c.logError(err, method, "get")
+	}
+
+	// The first caller's cancellation doesn't fail the callers sharing its
+	// request. The request is still bounded by the client's request timeout.
+	flight := c.flights.DoChan(key, func() (interface{}, error) {
+		out, err := fn(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.cache.Set(context.WithoutCancel(ctx), key, b, ttl)
+		if err != nil {
+			c.logError(err, method, "set")
+		}
+
+		return b, nil
+	})
+
+	select {
+	case res := <-flight:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+
+		// Every caller decodes its own copy, so callers can't see each other's
+		// changes to the result.
+		return decodeCached[T](res.Val.([]byte))
+
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func decodeCached[T any](b []byte) (T, error) {
+	var out T
+
+	err := json.Unmarshal(b, &out)
+
+	return out, err
+}
+
+func (c *CachingClient) logError(err error, method, op string) {
+	c.logger.Warn().
+		Err(err).
+		Str("method", method).
+		Str("op", op).
+		Msg("athenahealth cache error")
+}
@@ -0,0 +1,148 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingClient(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int32
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/departments"):
+			w.Write([]byte(`{"departments":[{"departmentid":"1","name":"Main"}],"totalcount":1}`))
+
+		case strings.HasSuffix(r.URL.Path, "/customfields"):
+			w.Write([]byte(`[{"customfieldid":"1","name":"Pronouns"}]`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+		}
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	c := NewCachingClient(athenaClient, cache.NewLRU(0)).
+		WithTTL("ListCustomFields", 0)
+
+	ctx := context.Background()
+
+	res, err := c.ListDepartments(ctx, nil)
+	assert.NoError(err)
+	assert.Equal("Main", res.Departments[0].Name)
+	assert.Equal(1, res.Pagination.TotalCount)
+
+	// Changes to a result don't leak into the cache.
+	res.Departments[0].Name = "Changed"
+
+	res, err = c.ListDepartments(ctx, nil)
+	assert.NoError(err)
+	assert.Equal("Main", res.Departments[0].Name)
+	assert.Equal(int32(1), requests.Load())
+
+	// Different arguments and practices are cached separately.
+	_, err = c.ListDepartments(ctx, &ListDepartmentsOptions{ShowAllDepartments: true})
+	assert.NoError(err)
+	assert.Equal(int32(2), requests.Load())
+
+	_, err = c.ListDepartments(ContextWithPracticeID(ctx, "2"), nil)
+	assert.NoError(err)
+	assert.Equal(int32(3), requests.Load())
+
+	assert.NoError(c.Invalidate(ctx, "ListDepartments"))
+
+	_, err = c.ListDepartments(ctx, nil)
+	assert.NoError(err)
+	assert.Equal(int32(4), requests.Load())
+
+	// The other practice's results are still cached.
+	_, err = c.ListDepartments(ContextWithPracticeID(ctx, "2"), nil)
+	assert.NoError(err)
+	assert.Equal(int32(4), requests.Load())
+
+	// Caching is disabled with a TTL of zero.
+	_, err = c.ListCustomFields(ctx)
+	assert.NoError(err)
+	_, err = c.ListCustomFields(ctx)
+	assert.NoError(err)
+	assert.Equal(int32(6), requests.Load())
+
+	// Errors are not cached.
+	_, err = c.GetProvider(ctx, "1")
+	assert.ErrorIs(err, ErrNotFound)
+	_, err = c.GetProvider(ctx, "1")
+	assert.ErrorIs(err, ErrNotFound)
+	assert.Equal(int32(8), requests.Load())
+}
+
+func TestCachingClient_coalesces(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int32
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		time.Sleep(20 * time.Millisecond)
+
+		w.Write([]byte(`{"providers":[{"providerid":1}]}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	c := NewCachingClient(athenaClient, cache.NewLRU(0))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := c.ListProviders(context.Background(), nil)
+			if assert.NoError(err) {
+				assert.Equal(1, res.Providers[0].ProviderID)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(int32(1), requests.Load())
+}
+
+func TestCachingClient_Invalidate_all(t *testing.T) {
+	assert := assert.New(t)
+
+	lru := cache.NewLRU(0)
+	ctx := context.Background()
+
+	assert.NoError(lru.Set(ctx, testPracticeID+":ListDepartments:null", []byte(`{}`), time.Hour))
+	assert.NoError(lru.Set(ctx, testPracticeID+":ListProviders:null", []byte(`{}`), time.Hour))
+	assert.NoError(lru.Set(ctx, "2:ListProviders:null", []byte(`{}`), time.Hour))
+
+	athenaClient, ts := testClient(nil)
+	defer ts.Close()
+
+	c := NewCachingClient(athenaClient, lru)
+
+	assert.NoError(c.Invalidate(ctx))
+	assert.Equal(1, lru.Len())
+}
@@ -0,0 +1,77 @@
+// Package cassette records requests made to the athenahealth API to a file and
+// replays them offline, so tests can run against realistic athena payloads.
+//
+// Use a Recorder as the transport of the http.Client given to
+// athenahealth.NewHTTPClient while talking to the preview environment, then
+// use a Replayer loaded from the saved file in tests. Bearer tokens,
+// credentials and PHI are scrubbed before interactions are recorded.
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+)
+
+var (
+	// ErrNoMatch is returned by Replayer when a request has no recorded
+	// interaction.
+	ErrNoMatch = errors.New("cassette: no recorded interaction matches request")
+	// ErrExhausted is returned by Replayer when every recorded interaction
+	// that matches a request has already been served.
+	ErrExhausted = errors.New("cassette: recorded interactions matching request exhausted")
+)
+
+// Cassette is a list of recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads a cassette from path.
+func Load(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{}
+
+	err = json.Unmarshal(b, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Save writes c to path.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0644)
+}
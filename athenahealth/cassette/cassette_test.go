@@ -0,0 +1,194 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// redirect returns a RoundTripper that sends every request to ts instead of
+// the host in its URL.
+func redirect(ts *httptest.Server) http.RoundTripper {
+	tsURL, _ := url.Parse(ts.URL)
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = tsURL.Scheme
+		req.URL.Host = tsURL.Host
+
+		return ts.Client().Transport.RoundTrip(req)
+	})
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/oauth2/v1/token":
+			w.Write([]byte(`{"access_token":"secret-token","expires_in":"3600"}`))
+
+		case "/v1/195900/patients/1":
+			assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+
+			w.Write([]byte(`[{"patientid":"1","firstname":"Jane","lastname":"Doe","dob":"01/02/1980","departmentid":"1"}]`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRecorder_Replayer(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := testServer(t)
+	defer ts.Close()
+
+	recorder := NewRecorder(redirect(ts))
+
+	athenaClient := athenahealth.NewHTTPClient(&http.Client{Transport: recorder}, "195900", "client-id", "client-secret")
+
+	patient, err := athenaClient.GetPatient(context.Background(), "1", nil)
+	assert.NoError(err)
+	assert.Equal("Jane", patient.FirstName)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	assert.NoError(recorder.Save(path))
+
+	b, err := os.ReadFile(path)
+	assert.NoError(err)
+
+	for _, secret := range []string{"secret-token", "client-secret", "Basic ", "patients/1", `"1"`, "Jane", "Doe", "01/02/1980"} {
+		assert.NotContains(string(b), secret)
+	}
+
+	c, err := Load(path)
+	assert.NoError(err)
+	assert.Len(c.Interactions, 2)
+
+	replayer := NewReplayer(c)
+
+	athenaClient = athenahealth.NewHTTPClient(&http.Client{Transport: replayer}, "195900", "client-id", "client-secret")
+
+	patient, err = athenaClient.GetPatient(context.Background(), "1", nil)
+	assert.NoError(err)
+	assert.Equal(Redacted, patient.PatientID)
+	assert.Equal(Redacted, patient.FirstName)
+	assert.Empty(replayer.Unused())
+
+	_, err = athenaClient.GetPatient(context.Background(), "1", nil)
+	assert.ErrorIs(err, ErrExhausted)
+
+	_, err = athenaClient.GetDepartment(context.Background(), "1")
+	assert.ErrorIs(err, ErrNoMatch)
+}
+
+func TestReplayer_match_order(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &Cassette{
+		Interactions: []*Interaction{
+			{
+				Request:  Request{Method: http.MethodGet, URL: "https://example.com/foo?b=2&a=1"},
+				Response: Response{StatusCode: http.StatusOK, Body: "first"},
+			},
+			{
+				Request:  Request{Method: http.MethodGet, URL: "https://example.com/foo?a=1&b=2"},
+				Response: Response{StatusCode: http.StatusOK, Body: "second"},
+			},
+		},
+	}
+
+	replayer := NewReplayer(c)
+	httpClient := &http.Client{Transport: replayer}
+
+	for _, expected := range []string{"first", "second"} {
+		res, err := httpClient.Get("http://localhost/foo?a=1&b=2")
+		assert.NoError(err)
+
+		b, err := io.ReadAll(res.Body)
+		assert.NoError(err)
+		assert.Equal(expected, string(b))
+	}
+
+	_, err := httpClient.Get("http://localhost/foo?a=1&b=2")
+	assert.ErrorIs(err, ErrExhausted)
+
+	_, err = httpClient.Post("http://localhost/foo?a=1&b=2", "text/plain", nil)
+	assert.ErrorIs(err, ErrNoMatch)
+}
+
+func TestScrubber(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newScrubber()
+	s.addKeys("MRN")
+	s.scrubbers = append(s.scrubbers, func(i *Interaction) {
+		i.Request.Header.Del("X-Request-Id")
+	})
+
+	i := &Interaction{
+		Request: Request{
+			Method: http.MethodPost,
+			URL:    "https://example.com/patients/123/documents/456?firstname=Jane&departmentid=1&patientid=123",
+			Header: http.Header{
+				"Authorization": {"Bearer token"},
+				"Content-Type":  {"application/x-www-form-urlencoded"},
+				"X-Request-Id":  {"foo"},
+			},
+			Body: "dob=01%2F02%2F1980&mrn=123&sex=F",
+		},
+		Response: Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Set-Cookie": {"session=foo"},
+			},
+			Body: `{"patients":[{"FirstName":"Jane","patientid":1,"insurances":[{"insuranceidnumber":"X1"}]}],"totalcount":1}`,
+		},
+	}
+
+	s.scrub(i)
+
+	assert.Equal("https://example.com/patients/REDACTED/documents/456?departmentid=1&firstname=REDACTED&patientid=REDACTED", i.Request.URL)
+	assert.Equal(http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, i.Request.Header)
+	assert.Equal("dob=REDACTED&mrn=REDACTED&sex=F", i.Request.Body)
+	assert.Empty(i.Response.Header)
+	assert.Equal(`{"patients":[{"FirstName":"REDACTED","insurances":[{"insuranceidnumber":"REDACTED"}],"patientid":"REDACTED"}],"totalcount":1}`, i.Response.Body)
+}
+
+func Test_scrubPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/v1/195900/chart/REDACTED/socialhistory", scrubPath("/v1/195900/chart/123/socialhistory"))
+	assert.Equal("/v1/195900/patients/REDACTED", scrubPath("/v1/195900/patients/123"))
+	assert.Equal("/v1/195900/patients/changed", scrubPath("/v1/195900/patients/changed"))
+	assert.Equal("/v1/195900/patients", scrubPath("/v1/195900/patients"))
+}
+
+func TestScrubber_not_json(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newScrubber()
+
+	i := &Interaction{
+		Response: Response{Body: "<html>Bad Gateway</html>"},
+	}
+
+	s.scrub(i)
+
+	assert.Equal("<html>Bad Gateway</html>", i.Response.Body)
+}
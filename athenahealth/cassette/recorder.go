@@ -0,0 +1,123 @@
+package cassette
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that sends requests with another
+// RoundTripper and records them, scrubbed, to a Cassette.
+type Recorder struct {
+	next http.RoundTripper
+
+	scrubber scrubber
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder returns a Recorder that sends requests with next. If next is
+// nil, http.DefaultTransport is used.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		next:     next,
+		scrubber: newScrubber(),
+		cassette: &Cassette{},
+	}
+}
+
+// WithRedactedKeys adds JSON, form and query keys to scrub in addition to
+// DefaultRedactedKeys.
+func (r *Recorder) WithRedactedKeys(keys ...string) *Recorder {
+	r.scrubber.addKeys(keys...)
+
+	return r
+}
+
+// WithScrubber adds a Scrubber that runs after the default scrubbing. Use the
+// same scrubbers with the Replayer so that requests still match.
+func (r *Recorder) WithScrubber(fn Scrubber) *Recorder {
+	r.scrubber.scrubbers = append(r.scrubber.scrubbers, fn)
+
+	return r
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	i := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       string(resBody),
+		},
+	}
+
+	r.scrubber.scrub(i)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{
+		Interactions: append([]*Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// readRequestBody reads the body of req and replaces it so req can still be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
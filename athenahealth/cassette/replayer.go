@@ -0,0 +1,149 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Replayer is an http.RoundTripper that serves responses from a Cassette
+// without sending requests. Requests are scrubbed like they were when
+// recorded and matched on method, path, query and body. Each interaction is
+// served once, in the order recorded. Requests that match no interaction fail
+// with ErrNoMatch, and requests whose matching interactions have all been
+// served fail with ErrExhausted.
+type Replayer struct {
+	cassette *Cassette
+
+	scrubber scrubber
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// NewReplayer returns a Replayer that serves the interactions in c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+		scrubber: newScrubber(),
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+// WithRedactedKeys adds keys to scrub from requests before matching. Use the
+// same keys the cassette was recorded with.
+func (r *Replayer) WithRedactedKeys(keys ...string) *Replayer {
+	r.scrubber.addKeys(keys...)
+
+	return r
+}
+
+// WithScrubber adds a Scrubber that runs on requests before matching. The
+// interaction passed to fn has an empty Response.
+func (r *Replayer) WithScrubber(fn Scrubber) *Replayer {
+	r.scrubber.scrubbers = append(r.scrubber.scrubbers, fn)
+
+	return r
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+	}
+
+	r.scrubber.scrub(i)
+
+	match, err := r.match(i.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody := []byte(match.Response.Body)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the first unused interaction that matches req.
+func (r *Replayer) match(req Request) (*Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := false
+
+	for idx, i := range r.cassette.Interactions {
+		if !requestsMatch(req, i.Request) {
+			continue
+		}
+
+		if !r.used[idx] {
+			r.used[idx] = true
+
+			return i, nil
+		}
+
+		matched = true
+	}
+
+	if matched {
+		return nil, fmt.Errorf("%w: %s %s", ErrExhausted, req.Method, req.URL)
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL)
+}
+
+// Unused returns the interactions that have not been served yet.
+func (r *Replayer) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []*Interaction
+
+	for idx, i := range r.cassette.Interactions {
+		if !r.used[idx] {
+			unused = append(unused, i)
+		}
+	}
+
+	return unused
+}
+
+func requestsMatch(a, b Request) bool {
+	if a.Method != b.Method || a.Body != b.Body {
+		return false
+	}
+
+	aURL, err := url.Parse(a.URL)
+	if err != nil {
+		return false
+	}
+
+	bURL, err := url.Parse(b.URL)
+	if err != nil {
+		return false
+	}
+
+	return aURL.Path == bURL.Path && aURL.Query().Encode() == bURL.Query().Encode()
+}
@@ -0,0 +1,275 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces scrubbed values.
+const Redacted = "REDACTED"
+
+// DefaultRedactedKeys are the JSON, form and query keys whose values are
+// scrubbed by default. Keys are matched case-insensitively.
+var DefaultRedactedKeys = []string{
+	// Credentials.
+	"access_token",
+	"refresh_token",
+	"client_secret",
+
+	// Patient identifiers and demographics.
+	"patientid",
+	"firstname",
+	"middlename",
+	"lastname",
+	"altfirstname",
+	"preferredname",
+	"suffix",
+	"dob",
+	"ssn",
+	"address",
+	"address1",
+	"address2",
+	"city",
+	"zip",
+	"email",
+	"lastemail",
+	"homephone",
+	"mobilephone",
+	"workphone",
+	"driverslicense",
+	"driverslicensenumber",
+	"driverslicenseurl",
+	"patientphoto",
+	"patientphotourl",
+
+	// Contacts, guardians and guarantors.
+	"contactname",
+	"contacthomephone",
+	"contactmobilephone",
+	"nextkinname",
+	"nextkinphone",
+	"guardianfirstname",
+	"guardianmiddlename",
+	"guardianlastname",
+	"guardiansuffix",
+	"guarantorfirstname",
+	"guarantormiddlename",
+	"guarantorlastname",
+	"guarantordob",
+	"guarantorssn",
+	"guarantoraddress",
+	"guarantoraddress1",
+	"guarantoraddress2",
+	"guarantorcity",
+	"guarantorzip",
+	"guarantoremail",
+	"guarantorphone",
+
+	// Insurance.
+	"insuranceidnumber",
+	"insurancepolicyholder",
+	"insurancepolicyholderfirstname",
+	"insurancepolicyholderlastname",
+	"insurancepolicyholderdob",
+	"insurancepolicyholderssn",
+	"insurancepolicyholderaddress",
+	"insurancepolicyholderaddress1",
+	"insurancepolicyholderaddress2",
+	"insurancepolicyholdercity",
+	"insurancepolicyholderzip",
+	"insuredfirstname",
+	"insuredlastname",
+	"insureddob",
+	"insuredaddress",
+	"insuredcity",
+	"insuredzip",
+	"policynumber",
+
+	// Free text and documents that commonly contain PHI.
+	"note",
+	"notes",
+	"notetext",
+	"appointmentnote",
+	"appointmentnotes",
+	"patientnote",
+	"internalnote",
+	"externalnote",
+	"labresultnote",
+	"linenote",
+	"sectionnote",
+	"receivernote",
+	"notefromlab",
+	"originalfilename",
+	"documentdata",
+	"attachmentcontents",
+}
+
+// patientPathSegments are the URL path segments that are followed by a patient
+// ID, e.g. /patients/{patientid} and /chart/{patientid}. The IDs are scrubbed.
+var patientPathSegments = []string{
+	"patients",
+	"chart",
+}
+
+// sensitiveHeaders are never recorded.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Scrubber modifies an interaction before it is recorded or matched.
+type Scrubber func(*Interaction)
+
+// scrubber holds the scrubbing configuration shared by Recorder and Replayer.
+type scrubber struct {
+	keys      map[string]bool
+	scrubbers []Scrubber
+}
+
+func newScrubber() scrubber {
+	s := scrubber{
+		keys: make(map[string]bool, len(DefaultRedactedKeys)),
+	}
+
+	s.addKeys(DefaultRedactedKeys...)
+
+	return s
+}
+
+func (s *scrubber) addKeys(keys ...string) {
+	for _, key := range keys {
+		s.keys[strings.ToLower(key)] = true
+	}
+}
+
+func (s *scrubber) scrub(i *Interaction) {
+	for _, header := range sensitiveHeaders {
+		i.Request.Header.Del(header)
+		i.Response.Header.Del(header)
+	}
+
+	u, err := url.Parse(i.Request.URL)
+	if err == nil {
+		u.Path = scrubPath(u.Path)
+		u.RawPath = ""
+
+		query := u.Query()
+		if len(query) > 0 {
+			s.scrubValues(query)
+			u.RawQuery = query.Encode()
+		}
+
+		i.Request.URL = u.String()
+	}
+
+	i.Request.Body = s.scrubBody(i.Request.Header, i.Request.Body)
+	i.Response.Body = s.scrubBody(i.Response.Header, i.Response.Body)
+
+	for _, fn := range s.scrubbers {
+		fn(i)
+	}
+}
+
+// scrubPath scrubs the patient IDs in p.
+func scrubPath(p string) string {
+	segments := strings.Split(p, "/")
+
+	for i := 1; i < len(segments); i++ {
+		if slices.Contains(patientPathSegments, segments[i-1]) && isID(segments[i]) {
+			segments[i] = Redacted
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// isID reports whether segment is a numeric athena ID, as opposed to e.g. the
+// "changed" of /patients/changed.
+func isID(segment string) bool {
+	if len(segment) == 0 {
+		return false
+	}
+
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *scrubber) scrubBody(header http.Header, body string) string {
+	if len(body) == 0 {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			return body
+		}
+
+		s.scrubValues(values)
+
+		return values.Encode()
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+
+	buf := &bytes.Buffer{}
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(s.scrubJSON(v)); err != nil {
+		return body
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (s *scrubber) scrubJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if s.keys[strings.ToLower(key)] {
+				v[key] = Redacted
+			} else {
+				v[key] = s.scrubJSON(value)
+			}
+		}
+
+	case []any:
+		for i, value := range v {
+			v[i] = s.scrubJSON(value)
+		}
+	}
+
+	return v
+}
+
+func (s *scrubber) scrubValues(values url.Values) {
+	for key, vals := range values {
+		if !s.keys[strings.ToLower(key)] {
+			continue
+		}
+
+		for i := range vals {
+			vals[i] = Redacted
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+)
+
+const (
+	defaultCircuitBreakerServerErrorRate  = 0.5
+	defaultCircuitBreakerTimeoutRate      = 0.5
+	defaultCircuitBreakerMinRequests      = 20
+	defaultCircuitBreakerWindow           = time.Minute
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+	defaultCircuitBreakerHalfOpenRequests = 1
+
+	// circuitBreakerBuckets is the number of buckets the window is divided
+	// into. Outcomes expire one bucket at a time.
+	circuitBreakerBuckets = 10
+)
+
+// ErrCircuitOpen is matched by errors returned while a circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitOpenError is returned, wrapped in an *APIError, without sending the
+// request while the circuit breaker for it is open.
+type CircuitOpenError struct {
+	// Scope identifies the circuit that is open.
+	Scope string
+	// RetryAfter is how long until the circuit lets a trial request through.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("athenahealth circuit breaker open for %s, retry after %s", e.Scope, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through and measures their outcomes.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests with a *CircuitOpenError.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets trial requests through to decide whether to close
+	// or open again.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerScope controls which requests share a circuit.
+type CircuitBreakerScope int
+
+const (
+	// CircuitBreakerScopeClient shares one circuit between all requests.
+	CircuitBreakerScopeClient CircuitBreakerScope = 0
+	// CircuitBreakerScopePractice uses a circuit per practice.
+	CircuitBreakerScopePractice CircuitBreakerScope = 1 << iota
+	// CircuitBreakerScopeEndpointGroup uses a circuit per EndpointGroup.
+	CircuitBreakerScopeEndpointGroup
+)
+
+// CircuitBreakerStatsRecorder is implemented by StatsRecorders that record
+// circuit breaker state changes.
+type CircuitBreakerStatsRecorder interface {
+	RecordCircuitBreakerStateChange(stats.CircuitBreakerStateChange) error
+}
+
+// CircuitBreaker stops sending requests to athena while it is failing. It
+// opens when the rate of 5xx responses and transport errors, or of timeouts,
+// in Window reaches ServerErrorRate or TimeoutRate, rejects requests for OpenDuration and then
+// lets HalfOpenRequests trial requests through. It closes if all of them
+// succeed and opens again otherwise.
+//
+// Configure a CircuitBreaker before passing it to WithCircuitBreaker. The zero
+// value never opens; use NewCircuitBreaker for the defaults.
+type CircuitBreaker struct {
+	// ServerErrorRate and TimeoutRate are fractions (0 to 1) of requests.
+	// Zero disables tripping on that kind of failure.
+	ServerErrorRate float64
+	TimeoutRate     float64
+
+	// MinRequests is the number of requests in Window needed before the
+	// breaker can open. A Window of zero or less defaults to a minute.
+	MinRequests int
+	Window      time.Duration
+
+	OpenDuration     time.Duration
+	HalfOpenRequests int
+
+	Scope CircuitBreakerScope
+
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens when half of at least
+// 20 requests in a minute fail with a 5xx or time out, and tries again after
+// 30 seconds.
+func NewCircuitBreaker(scope CircuitBreakerScope) *CircuitBreaker {
+	return &CircuitBreaker{
+		ServerErrorRate:  defaultCircuitBreakerServerErrorRate,
+		TimeoutRate:      defaultCircuitBreakerTimeoutRate,
+		MinRequests:      defaultCircuitBreakerMinRequests,
+		Window:           defaultCircuitBreakerWindow,
+		OpenDuration:     defaultCircuitBreakerOpenDuration,
+		HalfOpenRequests: defaultCircuitBreakerHalfOpenRequests,
+		Scope:            scope,
+
+		now:      time.Now,
+		circuits: map[string]*circuit{},
+	}
+}
+
+// State returns the state of the circuit identified by scope, as reported in
+// CircuitOpenError.Scope.
+func (cb *CircuitBreaker) State(scope string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[scope]
+	if !ok {
+		return CircuitClosed
+	}
+
+	return c.state
+}
+
+// outcome is the result of a request as far as the circuit breaker is concerned.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeServerError
+	outcomeTimeout
+	// outcomeIgnored is used for requests that were never sent to athena.
+	outcomeIgnored
+)
+
+type circuitBucket struct {
+	start        time.Time
+	requests     int
+	serverErrors int
+	timeouts     int
+}
+
+type circuit struct {
+	state    CircuitState
+	openedAt time.Time
+
+	buckets []circuitBucket
+
+	halfOpenInFlight  int
+	halfOpenSucceeded int
+}
+
+// stateChange describes a circuit changing state.
+type stateChange struct {
+	scope    string
+	from, to CircuitState
+}
+
+func (cb *CircuitBreaker) circuit(scope string) *circuit {
+	if cb.circuits == nil {
+		cb.circuits = map[string]*circuit{}
+	}
+
+	c, ok := cb.circuits[scope]
+	if !ok {
+		c = &circuit{
+			state:   CircuitClosed,
+			buckets: make([]circuitBucket, circuitBreakerBuckets),
+		}
+		cb.circuits[scope] = c
+	}
+
+	return c
+}
+
+func (cb *CircuitBreaker) window() time.Duration {
+	if cb.Window <= 0 {
+		return defaultCircuitBreakerWindow
+	}
+
+	return cb.Window
+}
+
+func (cb *CircuitBreaker) clock() time.Time {
+	if cb.now == nil {
+		return time.Now()
+	}
+
+	return cb.now()
+}
+
+// allow reports whether a request in scope may be sent, and whether it is a
+// half-open trial request.
+func (cb *CircuitBreaker) allow(scope string) (trial bool, change *stateChange, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(scope)
+
+	if c.state == CircuitOpen {
+		retryAfter := cb.OpenDuration - cb.clock().Sub(c.openedAt)
+		if retryAfter > 0 {
+			return false, nil, &CircuitOpenError{Scope: scope, RetryAfter: retryAfter}
+		}
+
+		change = cb.transition(scope, c, CircuitHalfOpen)
+	}
+
+	if c.state == CircuitHalfOpen {
+		if c.halfOpenInFlight+c.halfOpenSucceeded >= max(cb.HalfOpenRequests, 1) {
+			return false, change, &CircuitOpenError{Scope: scope}
+		}
+
+		c.halfOpenInFlight++
+
+		return true, change, nil
+	}
+
+	return false, change, nil
+}
+
+// record records the outcome of a request in scope that allow let through.
+// Outcomes of requests sent before the circuit last changed state are ignored.
+func (cb *CircuitBreaker) record(scope string, trial bool, o outcome) *stateChange {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(scope)
+
+	if trial != (c.state == CircuitHalfOpen) {
+		return nil
+	}
+
+	switch c.state {
+	case CircuitHalfOpen:
+		c.halfOpenInFlight--
+
+		switch o {
+		case outcomeIgnored:
+			return nil
+
+		case outcomeSuccess:
+			c.halfOpenSucceeded++
+			if c.halfOpenSucceeded >= max(cb.HalfOpenRequests, 1) {
+				return cb.transition(scope, c, CircuitClosed)
+			}
+
+			return nil
+		}
+
+		return cb.transition(scope, c, CircuitOpen)
+
+	case CircuitClosed:
+		if o == outcomeIgnored {
+			return nil
+		}
+
+		b := cb.bucket(c)
+		b.requests++
+
+		switch o {
+		case outcomeServerError:
+			b.serverErrors++
+
+		case outcomeTimeout:
+			b.timeouts++
+
+		default:
+			return nil
+		}
+
+		if cb.tripped(c) {
+			return cb.transition(scope, c, CircuitOpen)
+		}
+	}
+
+	return nil
+}
+
+// bucket returns the bucket for the current time, expiring old buckets.
+func (cb *CircuitBreaker) bucket(c *circuit) *circuitBucket {
+	now := cb.clock()
+	width := cb.window() / circuitBreakerBuckets
+	start := now.Truncate(width)
+
+	b := &c.buckets[int(now.UnixNano()/int64(width))%circuitBreakerBuckets]
+	if !b.start.Equal(start) {
+		*b = circuitBucket{start: start}
+	}
+
+	return b
+}
+
+func (cb *CircuitBreaker) tripped(c *circuit) bool {
+	var requests, serverErrors, timeouts int
+
+	cutoff := cb.clock().Add(-cb.window())
+
+	for _, b := range c.buckets {
+		if b.start.After(cutoff) {
+			requests += b.requests
+			serverErrors += b.serverErrors
+			timeouts += b.timeouts
+		}
+	}
+
+	if requests == 0 || requests < cb.MinRequests {
+		return false
+	}
+
+	if cb.ServerErrorRate > 0 && float64(serverErrors)/float64(requests) >= cb.ServerErrorRate {
+		return true
+	}
+
+	return cb.TimeoutRate > 0 && float64(timeouts)/float64(requests) >= cb.TimeoutRate
+}
+
+func (cb *CircuitBreaker) transition(scope string, c *circuit, to CircuitState) *stateChange {
+	change := &stateChange{scope: scope, from: c.state, to: to}
+
+	c.state = to
+	c.halfOpenInFlight = 0
+	c.halfOpenSucceeded = 0
+
+	switch to {
+	case CircuitOpen:
+		c.openedAt = cb.clock()
+
+	case CircuitClosed:
+		for i := range c.buckets {
+			c.buckets[i] = circuitBucket{}
+		}
+	}
+
+	return change
+}
+
+// requestOutcome classifies the result of a request. Transport errors, e.g. a
+// refused connection, count as server errors; canceled requests are ignored.
+func requestOutcome(res *http.Response, err error) outcome {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return outcomeIgnored
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			return outcomeTimeout
+		}
+
+		netErr := net.Error(nil)
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return outcomeTimeout
+		}
+
+		return outcomeServerError
+	}
+
+	if res != nil && res.StatusCode >= http.StatusInternalServerError {
+		return outcomeServerError
+	}
+
+	return outcomeSuccess
+}
+
+// WithCircuitBreaker configures a CircuitBreaker that stops requests from being
+// sent to athena while it is failing. Practice views share the breaker; use
+// CircuitBreakerScopePractice to give each practice its own circuit.
+func (h *HTTPClient) WithCircuitBreaker(cb *CircuitBreaker) *HTTPClient {
+	h.circuitBreaker = cb
+
+	return h
+}
+
+// circuitScope returns the scope of the circuit that a request to path with
+// method belongs to.
+func (h *HTTPClient) circuitScope(method, path string) (scope string, group EndpointGroup) {
+	group = h.endpointGrouper(method, path)
+
+	var parts []string
+
+	if h.circuitBreaker.Scope&CircuitBreakerScopePractice != 0 {
+		parts = append(parts, "practice:"+h.practiceID)
+	}
+
+	if h.circuitBreaker.Scope&CircuitBreakerScopeEndpointGroup != 0 {
+		parts = append(parts, "group:"+string(group))
+	}
+
+	if len(parts) == 0 {
+		return "client", group
+	}
+
+	return strings.Join(parts, ","), group
+}
+
+func (h *HTTPClient) circuitBreakerMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if h.circuitBreaker == nil {
+			return next.Do(req)
+		}
+
+		info := requestInfoFromRequest(req)
+		scope, group := h.circuitScope(req.Method, info.path)
+
+		trial, change, err := h.circuitBreaker.allow(scope)
+		h.reportCircuitStateChange(change, group)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := next.Do(req)
+
+		o := requestOutcome(res, err)
+		if !info.sent {
+			o = outcomeIgnored
+		}
+
+		h.reportCircuitStateChange(h.circuitBreaker.record(scope, trial, o), group)
+
+		return res, err
+	})
+}
+
+func (h *HTTPClient) reportCircuitStateChange(change *stateChange, group EndpointGroup) {
+	if change == nil {
+		return
+	}
+
+	event := h.logger.Info()
+	if change.to == CircuitOpen {
+		event = h.logger.Warn()
+	}
+
+	event.
+		Str("scope", change.scope).
+		Str("from", string(change.from)).
+		Str("to", string(change.to)).
+		Msg("athenahealth circuit breaker state changed")
+
+	recorder, ok := h.stats.(CircuitBreakerStatsRecorder)
+	if !ok {
+		return
+	}
+
+	err := recorder.RecordCircuitBreakerStateChange(stats.CircuitBreakerStateChange{
+		Scope:      change.scope,
+		PracticeID: h.practiceID,
+		Group:      string(group),
+		From:       string(change.from),
+		To:         string(change.to),
+	})
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("athenahealth stats error")
+	}
+}
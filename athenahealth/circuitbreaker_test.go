@@ -0,0 +1,246 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCircuitStatsRecorder struct {
+	testStatsRecorder
+
+	stateChanges []stats.CircuitBreakerStateChange
+}
+
+func (t *testCircuitStatsRecorder) RecordCircuitBreakerStateChange(change stats.CircuitBreakerStateChange) error {
+	t.stateChanges = append(t.stateChanges, change)
+
+	return nil
+}
+
+func testCircuitBreaker(scope CircuitBreakerScope) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cb := NewCircuitBreaker(scope)
+	cb.MinRequests = 4
+	cb.now = func() time.Time {
+		return now
+	}
+
+	return cb, &now
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	cb, now := testCircuitBreaker(CircuitBreakerScopeClient)
+
+	record := func(o outcome) *stateChange {
+		trial, _, err := cb.allow("client")
+		assert.NoError(err)
+
+		return cb.record("client", trial, o)
+	}
+
+	assert.Nil(record(outcomeSuccess))
+	assert.Nil(record(outcomeServerError))
+	assert.Nil(record(outcomeIgnored))
+	assert.Nil(record(outcomeSuccess))
+	assert.Equal(&stateChange{scope: "client", from: CircuitClosed, to: CircuitOpen}, record(outcomeServerError))
+	assert.Equal(CircuitOpen, cb.State("client"))
+
+	*now = now.Add(10 * time.Second)
+
+	_, _, err := cb.allow("client")
+	assert.ErrorIs(err, ErrCircuitOpen)
+
+	openErr := &CircuitOpenError{}
+	assert.True(errors.As(err, &openErr))
+	assert.Equal(20*time.Second, openErr.RetryAfter)
+
+	*now = now.Add(20 * time.Second)
+
+	// One trial request is let through.
+	trial, change, err := cb.allow("client")
+	assert.NoError(err)
+	assert.True(trial)
+	assert.Equal(&stateChange{scope: "client", from: CircuitOpen, to: CircuitHalfOpen}, change)
+
+	_, _, err = cb.allow("client")
+	assert.ErrorIs(err, ErrCircuitOpen)
+
+	// A failed trial opens the circuit again.
+	assert.Equal(&stateChange{scope: "client", from: CircuitHalfOpen, to: CircuitOpen}, cb.record("client", trial, outcomeTimeout))
+
+	*now = now.Add(30 * time.Second)
+
+	trial, _, err = cb.allow("client")
+	assert.NoError(err)
+
+	// Requests sent before the circuit opened don't affect the trial.
+	assert.Nil(cb.record("client", false, outcomeServerError))
+	assert.Equal(&stateChange{scope: "client", from: CircuitHalfOpen, to: CircuitClosed}, cb.record("client", trial, outcomeSuccess))
+	assert.Equal(CircuitClosed, cb.State("client"))
+}
+
+func TestCircuitBreaker_window(t *testing.T) {
+	assert := assert.New(t)
+
+	cb, now := testCircuitBreaker(CircuitBreakerScopeClient)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(cb.record("client", false, outcomeServerError))
+	}
+
+	// The failures above expire before the next one.
+	*now = now.Add(cb.Window)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(cb.record("client", false, outcomeSuccess))
+	}
+
+	assert.Nil(cb.record("client", false, outcomeServerError))
+	assert.Equal(CircuitClosed, cb.State("client"))
+}
+
+func TestCircuitBreaker_timeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	cb, _ := testCircuitBreaker(CircuitBreakerScopeClient)
+	cb.ServerErrorRate = 0
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(cb.record("client", false, outcomeServerError))
+	}
+
+	cb.record("client", false, outcomeTimeout)
+	cb.record("client", false, outcomeTimeout)
+	cb.record("client", false, outcomeTimeout)
+	assert.Equal(CircuitClosed, cb.State("client"))
+
+	cb.record("client", false, outcomeTimeout)
+	assert.Equal(CircuitOpen, cb.State("client"))
+}
+
+func Test_requestOutcome(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(outcomeSuccess, requestOutcome(&http.Response{StatusCode: http.StatusOK}, nil))
+	assert.Equal(outcomeSuccess, requestOutcome(&http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.Equal(outcomeServerError, requestOutcome(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.Equal(outcomeTimeout, requestOutcome(nil, context.DeadlineExceeded))
+	assert.Equal(outcomeIgnored, requestOutcome(nil, context.Canceled))
+	assert.Equal(outcomeServerError, requestOutcome(nil, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	assert.Equal(outcomeServerError, requestOutcome(nil, &net.DNSError{Err: "no such host", Name: "api.platform.athenahealth.com"}))
+}
+
+func TestCircuitBreaker_zeroValue(t *testing.T) {
+	assert := assert.New(t)
+
+	cb := &CircuitBreaker{ServerErrorRate: 0.5, MinRequests: 2}
+
+	for i := 0; i < 2; i++ {
+		trial, _, err := cb.allow("client")
+		assert.NoError(err)
+
+		cb.record("client", trial, outcomeServerError)
+	}
+
+	assert.Equal(CircuitOpen, cb.State("client"))
+}
+
+func TestHTTPClient_WithCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := int32(0)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if strings.HasSuffix(r.URL.Path, "/patients/1/documents") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{}`))
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	cb, _ := testCircuitBreaker(CircuitBreakerScopePractice | CircuitBreakerScopeEndpointGroup)
+	recorder := &testCircuitStatsRecorder{}
+
+	athenaClient.WithCircuitBreaker(cb).WithStatsRecorder(recorder)
+
+	for i := 0; i < 4; i++ {
+		_, err := athenaClient.Get(context.Background(), "/patients/1/documents", nil, nil)
+		assert.ErrorIs(err, ErrUpstreamUnavailable)
+	}
+
+	_, err := athenaClient.Get(context.Background(), "/patients/1/documents", nil, nil)
+	assert.ErrorIs(err, ErrCircuitOpen)
+	assert.Equal(int32(4), atomic.LoadInt32(&requests))
+
+	// Other endpoint groups and practices have their own circuits.
+	_, err = athenaClient.Get(context.Background(), "/appointments/1", nil, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.ForPractice("1").Get(context.Background(), "/patients/1/documents", nil, nil)
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+	assert.Equal(int32(6), atomic.LoadInt32(&requests))
+
+	assert.Equal([]stats.CircuitBreakerStateChange{{
+		Scope:      "practice:" + testPracticeID + ",group:documents",
+		PracticeID: testPracticeID,
+		Group:      "documents",
+		From:       "closed",
+		To:         "open",
+	}}, recorder.stateChanges)
+}
+
+func TestHTTPClient_WithCircuitBreaker_streamedBody(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	cb, _ := testCircuitBreaker(CircuitBreakerScopeClient)
+	athenaClient.WithCircuitBreaker(cb)
+
+	for i := 0; i < 4; i++ {
+		_, err := athenaClient.Get(context.Background(), "/departments", nil, nil)
+		assert.ErrorIs(err, ErrUpstreamUnavailable)
+	}
+
+	for i := 0; i < 3; i++ {
+		form := NewFormURLEncoder()
+		form.AddReader("attachmentcontents", strings.NewReader("document"))
+
+		_, err := athenaClient.PostFormReader(context.Background(), "/patients/1/documents", form, nil)
+		assert.ErrorIs(err, ErrCircuitOpen)
+	}
+
+	// Nothing reads the bodies of rejected requests, so their encoders must be
+	// stopped.
+	assert.Eventually(func() bool {
+		buf := make([]byte, 1<<20)
+		buf = buf[:runtime.Stack(buf, true)]
+
+		return !strings.Contains(string(buf), "(*formURLEncoder).newReader")
+	}, time.Second, 10*time.Millisecond)
+}
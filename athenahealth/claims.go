@@ -52,6 +52,8 @@ type createClaimResponse struct {
 }
 
 func (h *HTTPClient) CreateFinancialClaim(ctx context.Context, opts *CreateClaimOptions) ([]string, error) {
+	ctx = contextWithAuditOperation(ctx, "CreateFinancialClaim")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -108,18 +110,20 @@ func (h *HTTPClient) CreateFinancialClaim(ctx context.Context, opts *CreateClaim
 
 	form.Add("supervisingproviderid", opts.SupervisingProviderID)
 
-	res := &createClaimResponse{}
+	return idempotent(ctx, h, "CreateFinancialClaim", func(ctx context.Context) ([]string, error) {
+		res := &createClaimResponse{}
 
-	_, err = h.PostForm(ctx, "/claims", form, res)
-	if err != nil {
-		return []string{}, err
-	}
+		httpRes, err := h.PostForm(ctx, "/claims", form, res)
+		if err != nil {
+			return []string{}, err
+		}
 
-	if !res.Success {
-		return []string{}, errors.New(res.ErrorMessage)
-	}
+		if !res.Success {
+			return []string{}, rejectedError(httpRes, res.ErrorMessage)
+		}
 
-	return res.ClaimIDs, nil
+		return res.ClaimIDs, nil
+	})
 }
 
 type ClaimProcedure struct {
@@ -180,6 +184,8 @@ type listClaimsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/claim#Get-list-of-claim-details
 func (h *HTTPClient) ListClaims(ctx context.Context, opts *ListClaimsOptions) (*ListClaimsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListClaims")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -231,3 +237,40 @@ func (h *HTTPClient) ListClaims(ctx context.Context, opts *ListClaimsOptions) (*
 		Pagination: makePaginationResult(out.Next, out.Previous, out.TotalCount),
 	}, nil
 }
+
+// ListClaimsPager returns a Pager over the claims returned by ListClaims.
+func (h *HTTPClient) ListClaimsPager(opts *ListClaimsOptions) *Pager[*Claim] {
+	return newPager(h, "ListClaimsPager", func(ctx context.Context) ([]*Claim, *PaginationResult, error) {
+		res, err := h.ListClaims(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Claims, res.Pagination, nil
+	}, func(out *listClaimsResponse) ([]*Claim, *PaginationResult) {
+		return out.Claims, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
+// ListClaimsBulk returns every claim returned by ListClaims, fetching
+// the pages after the first concurrently. See BulkListOptions.
+func (h *HTTPClient) ListClaimsBulk(ctx context.Context, opts *ListClaimsOptions, bulkOpts *BulkListOptions) ([]*Claim, error) {
+	ctx = contextWithAuditOperation(ctx, "ListClaimsBulk")
+
+	pageOpts := ListClaimsOptions{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+
+	return bulkList(ctx, pageOpts.Pagination, bulkOpts, func(ctx context.Context, pagination *PaginationOptions) ([]*Claim, *PaginationResult, error) {
+		pageOpts := pageOpts
+		pageOpts.Pagination = pagination
+
+		res, err := h.ListClaims(ctx, &pageOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Claims, res.Pagination, nil
+	})
+}
@@ -107,6 +107,22 @@ func TestHTTPClient_CreateClaim(t *testing.T) {
 	assert.Len(claimIDs, 2)
 }
 
+func TestHTTPClient_CreateClaim_error(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := os.ReadFile("./resources/CreateClaimError.json")
+		w.Write(b)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.CreateFinancialClaim(context.Background(), &CreateClaimOptions{})
+	assert.ErrorIs(err, ErrRejected)
+	assert.ErrorContains(err, "Claims cannot be created for patients without an active insurance")
+}
+
 func TestHTTPClient_ListClaims(t *testing.T) {
 	assert := assert.New(t)
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"io"
 	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
 )
 
 // Client describes a client for the athenahealth API.
@@ -141,12 +144,115 @@ type TokenCacher interface {
 	Set(context.Context, string, time.Time) error
 }
 
+// TokenInvalidator is implemented by TokenCachers that can remove a cached token.
+// HTTPClient invalidates the cached token when athena rejects it.
+type TokenInvalidator interface {
+	Invalidate(context.Context) error
+}
+
+// RateLimiter is the original rate limiter contract. It is still accepted by
+// WithRateLimiter and adapted to RequestRateLimiter, but it only knows the
+// environment of requests.
 type RateLimiter interface {
 	Allowed(ctx context.Context, preview bool) (retryAfter time.Duration, err error)
 }
 
+// RequestRateLimiter rate limits requests by environment, practice and
+// endpoint. Requests wait retryAfter and are limited again when it returns
+// ratelimiter.ErrRateExceeded.
+type RequestRateLimiter interface {
+	AllowRequest(ctx context.Context, req ratelimiter.Request) (retryAfter time.Duration, err error)
+}
+
+// AdaptiveRateLimiter is implemented by RequestRateLimiters that adjust their
+// rate to athena's responses, e.g. ratelimiter.Adaptive.
+type AdaptiveRateLimiter interface {
+	// Observe is called with the status code of every response. It returns
+	// the rate in requests per second allowed afterwards.
+	Observe(ctx context.Context, req ratelimiter.Request, statusCode int) (rate float64, err error)
+}
+
+// RateLimitStatsRecorder is implemented by StatsRecorders that record the rate
+// of AdaptiveRateLimiters.
+type RateLimitStatsRecorder interface {
+	RecordRateLimitRate(stats.RateLimitRate) error
+}
+
+// legacyRateLimiter adapts a RateLimiter to RequestRateLimiter.
+type legacyRateLimiter struct {
+	RateLimiter
+}
+
+func (l *legacyRateLimiter) AllowRequest(ctx context.Context, req ratelimiter.Request) (time.Duration, error) {
+	return l.Allowed(ctx, req.Preview)
+}
+
+// newRequestRateLimiter returns r if it implements RequestRateLimiter,
+// otherwise it adapts r to RequestRateLimiter.
+func newRequestRateLimiter(r RateLimiter) RequestRateLimiter {
+	if limiter, ok := r.(RequestRateLimiter); ok {
+		return limiter
+	}
+
+	return &legacyRateLimiter{RateLimiter: r}
+}
+
+// Stats is the original stats contract. It is still accepted by WithStats and
+// adapted to StatsRecorder, but it only counts requests and responses.
 type Stats interface {
 	Request(method, path string) error
 	ResponseSuccess() error
 	ResponseError() error
 }
+
+// StatsRecorder records detailed stats about requests made to the athenahealth API.
+type StatsRecorder interface {
+	// RecordResponse is called after every request attempt, including attempts
+	// that failed without a response.
+	RecordResponse(stats.Response) error
+	// RecordRateLimitWait is called every time a request waits on the rate limiter.
+	RecordRateLimitWait(stats.RateLimitWait) error
+	// RecordTokenRefresh is called every time a new token is requested.
+	RecordTokenRefresh(stats.TokenRefresh) error
+}
+
+// legacyStats adapts a Stats to StatsRecorder.
+type legacyStats struct {
+	Stats
+}
+
+func (l *legacyStats) RecordResponse(res stats.Response) error {
+	// Stats was never told about attempts that failed without a response.
+	if res.StatusCode == 0 {
+		return nil
+	}
+
+	err := l.Request(res.Method, res.Path)
+	if err != nil {
+		return err
+	}
+
+	if res.Success() {
+		return l.ResponseSuccess()
+	}
+
+	return l.ResponseError()
+}
+
+func (l *legacyStats) RecordRateLimitWait(stats.RateLimitWait) error {
+	return nil
+}
+
+func (l *legacyStats) RecordTokenRefresh(stats.TokenRefresh) error {
+	return nil
+}
+
+// newStatsRecorder returns s if it implements StatsRecorder, otherwise it
+// adapts s to StatsRecorder.
+func newStatsRecorder(s Stats) StatsRecorder {
+	if recorder, ok := s.(StatsRecorder); ok {
+		return recorder
+	}
+
+	return &legacyStats{Stats: s}
+}
@@ -0,0 +1,383 @@
+package athenahealth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment names accepted by Config.Environment.
+const (
+	ConfigEnvironmentPreview = "preview"
+	ConfigEnvironmentProd    = "prod"
+	ConfigEnvironmentCustom  = "custom"
+)
+
+// Stats backends accepted by Config.Stats.Backend.
+const (
+	ConfigStatsNone       = ""
+	ConfigStatsDatadog    = "datadog"
+	ConfigStatsPrometheus = "prometheus"
+)
+
+// Config describes a fully wired HTTPClient. Load it with LoadConfig or
+// ConfigFromEnv, or fill it in directly, and build the client with
+// Config.NewHTTPClient.
+type Config struct {
+	PracticeID string `json:"practice_id" yaml:"practice_id"`
+	ClientID   string `json:"client_id" yaml:"client_id"`
+	Secret     string `json:"secret" yaml:"secret"`
+
+	// Environment is "preview" (the default), "prod" or "custom". Custom
+	// environments require BaseURL and AuthURL.
+	Environment string `json:"environment" yaml:"environment"`
+	// BaseURL, AuthURL and Scope override the environment's.
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	AuthURL string `json:"auth_url" yaml:"auth_url"`
+	Scope   string `json:"scope" yaml:"scope"`
+
+	// RequestTimeout is a duration such as "15s". Zero uses the default.
+	RequestTimeout Duration `json:"request_timeout" yaml:"request_timeout"`
+
+	// Redis is used to cache tokens and rate limit requests if Addr is set.
+	Redis RedisConfig `json:"redis" yaml:"redis"`
+
+	// RateLimit is the rate limit in requests per second. Zero uses the
+	// environment's RateLimit. Requests are rate limited in Redis if it is
+	// configured, and in memory otherwise.
+	RateLimit int `json:"rate_limit" yaml:"rate_limit"`
+
+	Stats StatsConfig `json:"stats" yaml:"stats"`
+}
+
+// RedisConfig configures the Redis client used for token caching and rate
+// limiting.
+type RedisConfig struct {
+	Addr     string `json:"addr" yaml:"addr"`
+	Password string `json:"password" yaml:"password"`
+	DB       int    `json:"db" yaml:"db"`
+
+	// TokenKey is the key tokens are cached under. Empty uses
+	// tokencacher.RedisDefaultKey.
+	TokenKey string `json:"token_key" yaml:"token_key"`
+}
+
+// StatsConfig configures where stats are recorded.
+type StatsConfig struct {
+	// Backend is "" (no stats), "datadog" or "prometheus". Prometheus metrics
+	// are registered on prometheus.DefaultRegisterer.
+	Backend string `json:"backend" yaml:"backend"`
+
+	// DatadogAddr is the address of the DogStatsD agent, e.g. localhost:8125.
+	DatadogAddr string `json:"datadog_addr" yaml:"datadog_addr"`
+}
+
+// Duration is a time.Duration that is decoded from strings such as "15s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	d.Duration = parsed
+
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// ConfigError lists the problems found while loading or validating a Config.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("athenahealth: invalid config: %s", strings.Join(e.Problems, "; "))
+}
+
+// LoadConfig reads a Config from a JSON (.json) or YAML file. Unknown keys,
+// e.g. misspelled ones, are reported as problems.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+
+		err = dec.Decode(c)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+
+		err = dec.Decode(c)
+		if errors.Is(err, io.EOF) {
+			// The file is empty.
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, &ConfigError{Problems: []string{fmt.Sprintf("%s: %s", path, err)}}
+	}
+
+	return c, nil
+}
+
+// ConfigFromEnv reads a Config from environment variables:
+//
+//	ATHENA_PRACTICE_ID, ATHENA_CLIENT_ID, ATHENA_SECRET,
+//	ATHENA_ENVIRONMENT, ATHENA_BASE_URL, ATHENA_AUTH_URL, ATHENA_SCOPE,
+//	ATHENA_REQUEST_TIMEOUT, ATHENA_RATE_LIMIT,
+//	ATHENA_REDIS_ADDR, ATHENA_REDIS_PASSWORD, ATHENA_REDIS_DB, ATHENA_REDIS_TOKEN_KEY,
+//	ATHENA_STATS_BACKEND, ATHENA_DATADOG_ADDR
+func ConfigFromEnv() (*Config, error) {
+	c := &Config{}
+
+	stringVars := map[string]*string{
+		"ATHENA_PRACTICE_ID":     &c.PracticeID,
+		"ATHENA_CLIENT_ID":       &c.ClientID,
+		"ATHENA_SECRET":          &c.Secret,
+		"ATHENA_ENVIRONMENT":     &c.Environment,
+		"ATHENA_BASE_URL":        &c.BaseURL,
+		"ATHENA_AUTH_URL":        &c.AuthURL,
+		"ATHENA_SCOPE":           &c.Scope,
+		"ATHENA_REDIS_ADDR":      &c.Redis.Addr,
+		"ATHENA_REDIS_PASSWORD":  &c.Redis.Password,
+		"ATHENA_REDIS_TOKEN_KEY": &c.Redis.TokenKey,
+		"ATHENA_STATS_BACKEND":   &c.Stats.Backend,
+		"ATHENA_DATADOG_ADDR":    &c.Stats.DatadogAddr,
+	}
+
+	for name, field := range stringVars {
+		*field = os.Getenv(name)
+	}
+
+	var problems []string
+
+	intVars := []struct {
+		name  string
+		field *int
+	}{
+		{"ATHENA_RATE_LIMIT", &c.RateLimit},
+		{"ATHENA_REDIS_DB", &c.Redis.DB},
+	}
+
+	for _, intVar := range intVars {
+		name, field := intVar.name, intVar.field
+
+		v := os.Getenv(name)
+		if len(v) == 0 {
+			continue
+		}
+
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be an integer", name))
+			continue
+		}
+
+		*field = i
+	}
+
+	if v := os.Getenv("ATHENA_REQUEST_TIMEOUT"); len(v) > 0 {
+		err := c.RequestTimeout.UnmarshalText([]byte(v))
+		if err != nil {
+			problems = append(problems, "ATHENA_REQUEST_TIMEOUT must be a duration such as 15s")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+
+	return c, nil
+}
+
+// Validate returns a *ConfigError describing every problem with c.
+func (c *Config) Validate() error {
+	var problems []string
+
+	required := func(field, value string) {
+		if len(value) == 0 {
+			problems = append(problems, field+" is required")
+		}
+	}
+
+	validURL := func(field, value string) {
+		if len(value) == 0 {
+			return
+		}
+
+		u, err := url.Parse(value)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			problems = append(problems, field+" must be an absolute URL")
+		}
+	}
+
+	required("practice_id", c.PracticeID)
+	required("client_id", c.ClientID)
+	required("secret", c.Secret)
+
+	switch c.Environment {
+	case "", ConfigEnvironmentPreview, ConfigEnvironmentProd:
+
+	case ConfigEnvironmentCustom:
+		required("base_url", c.BaseURL)
+		required("auth_url", c.AuthURL)
+
+	default:
+		problems = append(problems, fmt.Sprintf("environment must be %q, %q or %q, got %q", ConfigEnvironmentPreview, ConfigEnvironmentProd, ConfigEnvironmentCustom, c.Environment))
+	}
+
+	validURL("base_url", c.BaseURL)
+	validURL("auth_url", c.AuthURL)
+
+	if c.RequestTimeout.Duration < 0 {
+		problems = append(problems, "request_timeout must not be negative")
+	}
+
+	if c.RateLimit < 0 {
+		problems = append(problems, "rate_limit must not be negative")
+	}
+
+	if c.Redis.DB < 0 {
+		problems = append(problems, "redis.db must not be negative")
+	}
+
+	switch c.Stats.Backend {
+	case ConfigStatsNone, ConfigStatsPrometheus:
+
+	case ConfigStatsDatadog:
+		required("stats.datadog_addr", c.Stats.DatadogAddr)
+
+	default:
+		problems = append(problems, fmt.Sprintf("stats.backend must be %q or %q, got %q", ConfigStatsDatadog, ConfigStatsPrometheus, c.Stats.Backend))
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+
+	return nil
+}
+
+// environment returns the Environment described by c.
+func (c *Config) environment() Environment {
+	env := PreviewEnvironment
+
+	switch c.Environment {
+	case ConfigEnvironmentProd:
+		env = ProdEnvironment
+
+	case ConfigEnvironmentCustom:
+		env = Environment{
+			Name:      ConfigEnvironmentCustom,
+			RateLimit: ProdEnvironment.RateLimit,
+		}
+	}
+
+	if len(c.BaseURL) > 0 {
+		env.BaseURL = c.BaseURL
+	}
+
+	if len(c.AuthURL) > 0 {
+		env.AuthURL = c.AuthURL
+	}
+
+	if len(c.Scope) > 0 {
+		env.Scope = c.Scope
+	}
+
+	if c.RateLimit > 0 {
+		env.RateLimit = c.RateLimit
+	}
+
+	return env
+}
+
+// NewHTTPClient validates c and returns an HTTPClient configured by it.
+// httpClient is used for API and token requests. Call the client's Close method
+// to close the Redis and statsd clients it creates.
+func (c *Config) NewHTTPClient(httpClient *http.Client) (*HTTPClient, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	env := c.environment()
+
+	h := NewHTTPClient(httpClient, c.PracticeID, c.ClientID, c.Secret).
+		WithEnvironment(env)
+
+	if c.RequestTimeout.Duration > 0 {
+		h.WithRequestTimeout(c.RequestTimeout.Duration)
+	}
+
+	if len(c.Redis.Addr) > 0 {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     c.Redis.Addr,
+			Password: c.Redis.Password,
+			DB:       c.Redis.DB,
+		})
+
+		h.closers = append(h.closers, redisClient)
+
+		h.WithTokenCacher(tokencacher.NewRedis(redisClient, c.Redis.TokenKey)).
+			WithRateLimiter(ratelimiter.NewRedis(redisClient, env.RateLimit, env.RateLimit))
+	} else {
+		h.WithRateLimiter(ratelimiter.NewMemory(env.RateLimit, env.RateLimit))
+	}
+
+	switch c.Stats.Backend {
+	case ConfigStatsDatadog:
+		statsdClient, err := statsd.New(c.Stats.DatadogAddr)
+		if err != nil {
+			h.Close()
+
+			return nil, &ConfigError{Problems: []string{fmt.Sprintf("stats.datadog_addr: %s", err)}}
+		}
+
+		h.closers = append(h.closers, statsdClient)
+
+		h.WithStatsRecorder(stats.NewDatadog(statsdClient))
+
+	case ConfigStatsPrometheus:
+		promStats, err := stats.NewPrometheus(prometheus.DefaultRegisterer)
+		if err != nil {
+			h.Close()
+
+			return nil, err
+		}
+
+		h.WithStatsRecorder(promStats)
+	}
+
+	return h, nil
+}
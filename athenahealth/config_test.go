@@ -0,0 +1,246 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "athena.yaml")
+	err := os.WriteFile(yamlPath, []byte(`
+practice_id: "195900"
+client_id: key
+secret: secret
+environment: prod
+request_timeout: 30s
+rate_limit: 50
+redis:
+  addr: localhost:6379
+  db: 2
+stats:
+  backend: prometheus
+`), 0600)
+	assert.NoError(err)
+
+	jsonPath := filepath.Join(dir, "athena.json")
+	err = os.WriteFile(jsonPath, []byte(`{
+		"practice_id": "195900",
+		"client_id": "key",
+		"secret": "secret",
+		"environment": "prod",
+		"request_timeout": "30s",
+		"rate_limit": 50,
+		"redis": {"addr": "localhost:6379", "db": 2},
+		"stats": {"backend": "prometheus"}
+	}`), 0600)
+	assert.NoError(err)
+
+	expected := &Config{
+		PracticeID:     "195900",
+		ClientID:       "key",
+		Secret:         "secret",
+		Environment:    ConfigEnvironmentProd,
+		RequestTimeout: Duration{30 * time.Second},
+		RateLimit:      50,
+		Redis:          RedisConfig{Addr: "localhost:6379", DB: 2},
+		Stats:          StatsConfig{Backend: ConfigStatsPrometheus},
+	}
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		c, err := LoadConfig(path)
+		assert.NoError(err, path)
+		assert.Equal(expected, c, path)
+	}
+
+	badPath := filepath.Join(dir, "bad.yaml")
+	err = os.WriteFile(badPath, []byte("request_timeout: soon"), 0600)
+	assert.NoError(err)
+
+	_, err = LoadConfig(badPath)
+	configErr := &ConfigError{}
+	assert.True(errors.As(err, &configErr))
+}
+
+func TestLoadConfig_unknownKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "athena.yaml")
+	err := os.WriteFile(yamlPath, []byte(`
+practice_id: "195900"
+redis:
+  adr: localhost:6379
+`), 0600)
+	assert.NoError(err)
+
+	jsonPath := filepath.Join(dir, "athena.json")
+	err = os.WriteFile(jsonPath, []byte(`{"practice_id": "195900", "ratelimit": 50}`), 0600)
+	assert.NoError(err)
+
+	_, err = LoadConfig(yamlPath)
+	configErr := &ConfigError{}
+	if assert.True(errors.As(err, &configErr)) {
+		assert.Contains(configErr.Problems[0], "adr")
+	}
+
+	_, err = LoadConfig(jsonPath)
+	if assert.True(errors.As(err, &configErr)) {
+		assert.Contains(configErr.Problems[0], "ratelimit")
+	}
+
+	// An empty file is an empty config.
+	emptyPath := filepath.Join(dir, "empty.yaml")
+	err = os.WriteFile(emptyPath, nil, 0600)
+	assert.NoError(err)
+
+	c, err := LoadConfig(emptyPath)
+	assert.NoError(err)
+	assert.Equal(&Config{}, c)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("ATHENA_PRACTICE_ID", "195900")
+	t.Setenv("ATHENA_CLIENT_ID", "key")
+	t.Setenv("ATHENA_SECRET", "secret")
+	t.Setenv("ATHENA_ENVIRONMENT", "custom")
+	t.Setenv("ATHENA_BASE_URL", "https://proxy.internal/v1/")
+	t.Setenv("ATHENA_AUTH_URL", "https://proxy.internal/oauth2/v1/token")
+	t.Setenv("ATHENA_REQUEST_TIMEOUT", "5s")
+	t.Setenv("ATHENA_REDIS_DB", "1")
+	t.Setenv("ATHENA_STATS_BACKEND", "datadog")
+	t.Setenv("ATHENA_DATADOG_ADDR", "localhost:8125")
+
+	c, err := ConfigFromEnv()
+	assert.NoError(err)
+
+	assert.Equal(&Config{
+		PracticeID:     "195900",
+		ClientID:       "key",
+		Secret:         "secret",
+		Environment:    ConfigEnvironmentCustom,
+		BaseURL:        "https://proxy.internal/v1/",
+		AuthURL:        "https://proxy.internal/oauth2/v1/token",
+		RequestTimeout: Duration{5 * time.Second},
+		Redis:          RedisConfig{DB: 1},
+		Stats:          StatsConfig{Backend: ConfigStatsDatadog, DatadogAddr: "localhost:8125"},
+	}, c)
+	assert.NoError(c.Validate())
+
+	t.Setenv("ATHENA_RATE_LIMIT", "fast")
+	t.Setenv("ATHENA_REQUEST_TIMEOUT", "soon")
+
+	_, err = ConfigFromEnv()
+	assert.EqualError(err, "athenahealth: invalid config: ATHENA_RATE_LIMIT must be an integer; ATHENA_REQUEST_TIMEOUT must be a duration such as 15s")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	err := (&Config{
+		Environment: "staging",
+		AuthURL:     "/token",
+		RateLimit:   -1,
+		Stats:       StatsConfig{Backend: "statsd"},
+	}).Validate()
+
+	configErr := &ConfigError{}
+	assert.True(errors.As(err, &configErr))
+	assert.Equal([]string{
+		"practice_id is required",
+		"client_id is required",
+		"secret is required",
+		`environment must be "preview", "prod" or "custom", got "staging"`,
+		"auth_url must be an absolute URL",
+		"rate_limit must not be negative",
+		`stats.backend must be "datadog" or "prometheus", got "statsd"`,
+	}, configErr.Problems)
+
+	err = (&Config{
+		PracticeID:  "195900",
+		ClientID:    "key",
+		Secret:      "secret",
+		Environment: ConfigEnvironmentCustom,
+		Stats:       StatsConfig{Backend: ConfigStatsDatadog},
+	}).Validate()
+	assert.EqualError(err, "athenahealth: invalid config: base_url is required; auth_url is required; stats.datadog_addr is required")
+}
+
+func TestConfig_NewHTTPClient(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	c := &Config{
+		PracticeID:     "195900",
+		ClientID:       "key",
+		Secret:         "secret",
+		Environment:    ConfigEnvironmentProd,
+		RequestTimeout: Duration{5 * time.Second},
+		Redis:          RedisConfig{Addr: s.Addr()},
+		Stats:          StatsConfig{Backend: ConfigStatsPrometheus},
+	}
+
+	athenaClient, err := c.NewHTTPClient(&http.Client{})
+	assert.NoError(err)
+
+	assert.Equal("195900", athenaClient.practiceID)
+	assert.Equal(ProdEnvironment, athenaClient.Environment())
+	assert.Equal(5*time.Second, athenaClient.requestTimeout)
+	assert.IsType(&tokencacher.Redis{}, athenaClient.tokenCacher)
+	assert.IsType(&ratelimiter.Redis{}, athenaClient.rateLimiter)
+	assert.IsType(&stats.Prometheus{}, athenaClient.stats)
+
+	assert.NoError(athenaClient.Close())
+
+	_, err = athenaClient.tokenCacher.Get(context.Background())
+	assert.EqualError(err, "redis: client is closed")
+
+	c.PracticeID = ""
+
+	_, err = c.NewHTTPClient(&http.Client{})
+	assert.EqualError(err, "athenahealth: invalid config: practice_id is required")
+}
+
+func TestConfig_NewHTTPClient_withoutRedis(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &Config{
+		PracticeID:  "195900",
+		ClientID:    "key",
+		Secret:      "secret",
+		Environment: ConfigEnvironmentPreview,
+		Stats:       StatsConfig{Backend: ConfigStatsDatadog, DatadogAddr: "localhost:8125"},
+	}
+
+	athenaClient, err := c.NewHTTPClient(&http.Client{})
+	assert.NoError(err)
+
+	assert.IsType(&tokencacher.Default{}, athenaClient.tokenCacher)
+	assert.IsType(&ratelimiter.Memory{}, athenaClient.rateLimiter)
+	assert.IsType(&stats.Datadog{}, athenaClient.stats)
+	assert.Len(athenaClient.closers, 1)
+
+	assert.NoError(athenaClient.Close())
+}
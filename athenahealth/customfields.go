@@ -32,6 +32,8 @@ type CustomField struct {
 //
 // https://docs.athenahealth.com/api/api-ref/custom-fields#Get-practice's-list-of-custom-fields
 func (h *HTTPClient) ListCustomFields(ctx context.Context) ([]*CustomField, error) {
+	ctx = contextWithAuditOperation(ctx, "ListCustomFields")
+
 	var out []*CustomField
 
 	_, err := h.Get(ctx, "/customfields", nil, &out)
@@ -0,0 +1,205 @@
+package athenahealth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeJSON decodes the JSON in r into out without reading all of r into
+// memory first. json.Decoder buffers a whole value before decoding it, so
+// arrays are decoded one element at a time instead: a top-level array, and the
+// array fields of a top-level object, which is where athena puts long lists.
+// Everything else is decoded with the standard encoding/json rules.
+func decodeJSON(r io.Reader, out interface{}) error {
+	dec := json.NewDecoder(r)
+
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return dec.Decode(out)
+	}
+
+	v = v.Elem()
+
+	// Follow pointers to pointers, e.g. &out where out is a *struct.
+	for v.Kind() == reflect.Ptr && !v.IsNil() && !implementsUnmarshaler(v.Type()) {
+		v = v.Elem()
+	}
+
+	if implementsUnmarshaler(v.Type()) || (v.Kind() != reflect.Slice && v.Kind() != reflect.Struct) {
+		return dec.Decode(v.Addr().Interface())
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok == nil {
+		v.Set(reflect.Zero(v.Type()))
+
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Slice && tok == json.Delim('['):
+		return decodeArrayElements(dec, v)
+
+	case v.Kind() == reflect.Struct && tok == json.Delim('{'):
+		return decodeObject(dec, v)
+	}
+
+	return &json.UnmarshalTypeError{Value: fmt.Sprint(tok), Type: v.Type(), Offset: dec.InputOffset()}
+}
+
+func implementsUnmarshaler(t reflect.Type) bool {
+	return t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// decodeArrayElements decodes the elements of an array whose opening bracket
+// has been read into v, a slice, one at a time.
+func decodeArrayElements(dec *json.Decoder, v reflect.Value) error {
+	zero := reflect.Zero(v.Type().Elem())
+
+	s := reflect.MakeSlice(v.Type(), 0, 0)
+
+	for dec.More() {
+		s = reflect.Append(s, zero)
+
+		err := dec.Decode(s.Index(s.Len() - 1).Addr().Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Closing bracket.
+	_, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	v.Set(s)
+
+	return nil
+}
+
+// decodeObject decodes an object whose opening brace has been read into v, a
+// struct. Array values of slice fields are decoded element by element; all
+// other members are collected and decoded together with json.Unmarshal.
+func decodeObject(dec *json.Decoder, v reflect.Value) error {
+	sliceFields := streamableFields(v)
+
+	rest := &bytes.Buffer{}
+	rest.WriteByte('{')
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key := tok.(string)
+
+		if field, ok := sliceFields[strings.ToLower(key)]; ok {
+			err = decodeField(dec, field)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		raw := json.RawMessage{}
+
+		err = dec.Decode(&raw)
+		if err != nil {
+			return err
+		}
+
+		if rest.Len() > 1 {
+			rest.WriteByte(',')
+		}
+
+		//nolint
+		encodedKey, _ := json.Marshal(key)
+
+		rest.Write(encodedKey)
+		rest.WriteByte(':')
+		rest.Write(raw)
+	}
+
+	// Closing brace.
+	_, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	rest.WriteByte('}')
+
+	return json.Unmarshal(rest.Bytes(), v.Addr().Interface())
+}
+
+// decodeField decodes the next value into field, a slice.
+func decodeField(dec *json.Decoder, field reflect.Value) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok == nil {
+		field.Set(reflect.Zero(field.Type()))
+
+		return nil
+	}
+
+	if tok != json.Delim('[') {
+		return &json.UnmarshalTypeError{Value: fmt.Sprint(tok), Type: field.Type(), Offset: dec.InputOffset()}
+	}
+
+	return decodeArrayElements(dec, field)
+}
+
+// streamableFields returns the exported slice fields of v, a struct, that can
+// be decoded element by element, keyed by their lowercased JSON name.
+func streamableFields(v reflect.Value) map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !f.IsExported() || f.Anonymous || f.Type.Kind() != reflect.Slice || implementsUnmarshaler(f.Type) {
+			continue
+		}
+
+		// []byte is decoded from a base64 string.
+		if f.Type.Elem().Kind() == reflect.Uint8 {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && len(opts) == 0 {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = f.Name
+		}
+
+		// ",string" only applies to scalars, but leave anything unusual to
+		// encoding/json.
+		if strings.Contains(opts, "string") {
+			continue
+		}
+
+		fields[strings.ToLower(name)] = v.Field(i)
+	}
+
+	return fields
+}
@@ -0,0 +1,127 @@
+package athenahealth
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertDecodesLikeUnmarshal asserts that decodeJSON decodes data into a new
+// value of out's type exactly like json.Unmarshal does.
+func assertDecodesLikeUnmarshal(t *testing.T, data string, newOut func() interface{}) {
+	t.Helper()
+
+	expected := newOut()
+	expectedErr := json.Unmarshal([]byte(data), expected)
+
+	actual := newOut()
+	actualErr := decodeJSON(strings.NewReader(data), actual)
+
+	if expectedErr != nil {
+		assert.Error(t, actualErr, data)
+		return
+	}
+
+	assert.NoError(t, actualErr, data)
+	assert.Equal(t, expected, actual, data)
+}
+
+func Test_decodeJSON_fixtures(t *testing.T) {
+	cases := map[string]func() interface{}{
+		"ListOpenAppointmentSlots.json": func() interface{} { return &listOpenAppointmentSlotsResponse{} },
+		"ListBookedAppointments.json":   func() interface{} { return &listBookedAppointmentsResponse{} },
+		"ListChangedLabResults.json":    func() interface{} { return &listChangedLabResultsResponse{} },
+		"ListEncounterDocuments.json":   func() interface{} { return &listEncounterDocumentsResponse{} },
+		"ListPatients.json":             func() interface{} { return &listPatientsResponse{} },
+		"ListDepartments.json":          func() interface{} { return &listDepartmentsResponse{} },
+		"ListClaims.json":               func() interface{} { return &listClaimsResponse{} },
+		"GetPatient.json":               func() interface{} { return &[]*Patient{} },
+		"GetAppointment.json":           func() interface{} { return &[]*Appointment{} },
+		"CreateClaim.json":              func() interface{} { return &createClaimResponse{} },
+		"EncounterSummary.json":         func() interface{} { return &EncounterSummaryResponse{} },
+	}
+
+	for fixture, newOut := range cases {
+		t.Run(fixture, func(t *testing.T) {
+			b, err := os.ReadFile("./resources/" + fixture)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(b, newOut()))
+
+			assertDecodesLikeUnmarshal(t, string(b), newOut)
+		})
+	}
+}
+
+func Test_decodeJSON(t *testing.T) {
+	type element struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	type response struct {
+		Elements   []element  `json:"elements"`
+		Pointers   []*element `json:"pointers"`
+		Names      []string
+		Data       []byte `json:"data"`
+		TotalCount int    `json:"totalcount"`
+		Ignored    []int  `json:"-"`
+
+		PaginationResponse
+	}
+
+	cases := []string{
+		`{"elements":[{"id":1,"name":"a"},{"id":2}],"pointers":[{"id":3},null],"names":["x"],"totalcount":2,"next":"/foo?offset=2"}`,
+		`{"ELEMENTS":[{"id":1}],"TotalCount":1}`,
+		`{"elements":null,"pointers":[]}`,
+		`{"data":"aGVsbG8=","-":[1]}`,
+		`{"elements":"foo"}`,
+		`{"totalcount":"foo"}`,
+		`[]`,
+		`null`,
+		`{"elements":[{"id":1}`,
+	}
+
+	for _, data := range cases {
+		assertDecodesLikeUnmarshal(t, data, func() interface{} { return &response{} })
+		assertDecodesLikeUnmarshal(t, "["+data+"]", func() interface{} { return &[]*response{} })
+	}
+
+	// Pointer to a pointer, as passed by some callers.
+	assertDecodesLikeUnmarshal(t, cases[0], func() interface{} {
+		out := &response{}
+		return &out
+	})
+
+	// Types decodeJSON doesn't stream.
+	assertDecodesLikeUnmarshal(t, cases[0], func() interface{} { return &map[string]interface{}{} })
+	assertDecodesLikeUnmarshal(t, `"1"`, func() interface{} { return new(NumberString) })
+	assertDecodesLikeUnmarshal(t, cases[0], func() interface{} {
+		var out interface{}
+		return &out
+	})
+}
+
+func Test_streamableFields(t *testing.T) {
+	assert := assert.New(t)
+
+	type response struct {
+		Elements []int `json:"elements,omitempty"`
+		Names    []string
+		Data     []byte          `json:"data"`
+		Quoted   []int           `json:"quoted,string"`
+		Ignored  []int           `json:"-"`
+		Raw      json.RawMessage `json:"raw"`
+		private  []int
+		Count    int `json:"count"`
+	}
+
+	fields := streamableFields(reflect.ValueOf(&response{}).Elem())
+
+	assert.Len(fields, 2)
+	assert.Contains(fields, "elements")
+	assert.Contains(fields, "names")
+}
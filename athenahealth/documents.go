@@ -56,6 +56,8 @@ type listAdminDocumentsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-admin-document#Get-list-of-patient's-admin-documents
 func (h *HTTPClient) ListAdminDocuments(ctx context.Context, patientID string, opts *ListAdminDocumentsOptions) (*ListAdminDocumentsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListAdminDocuments")
+
 	out := &listAdminDocumentsResponse{}
 
 	q := url.Values{}
@@ -87,6 +89,20 @@ func (h *HTTPClient) ListAdminDocuments(ctx context.Context, patientID string, o
 	}, nil
 }
 
+// ListAdminDocumentsPager returns a Pager over the admin documents returned by ListAdminDocuments.
+func (h *HTTPClient) ListAdminDocumentsPager(patientID string, opts *ListAdminDocumentsOptions) *Pager[*AdminDocument] {
+	return newPager(h, "ListAdminDocumentsPager", func(ctx context.Context) ([]*AdminDocument, *PaginationResult, error) {
+		res, err := h.ListAdminDocuments(ctx, patientID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.AdminDocuments, res.Pagination, nil
+	}, func(out *listAdminDocumentsResponse) ([]*AdminDocument, *PaginationResult) {
+		return out.AdminDocuments, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
 type AddDocumentOptions struct {
 	ActionNote         *string
 	AppointmentID      *int
@@ -99,7 +115,8 @@ type AddDocumentOptions struct {
 }
 
 type addDocumentResponse struct {
-	DocumentID string `json:"documentid"`
+	DocumentID   string `json:"documentid"`
+	ErrorMessage string `json:"errormessage"`
 }
 
 // AddDocument - Add document to patient's chart
@@ -135,6 +152,8 @@ type addDocumentResponse struct {
 // MEDICALRECORD_PATIENTDIARY
 // MEDICALRECORD_VACCINATION
 func (h *HTTPClient) AddDocument(ctx context.Context, patientID string, opts *AddDocumentOptions) (string, error) {
+	ctx = contextWithAuditOperation(ctx, "AddDocument")
+
 	var form url.Values
 
 	if opts != nil {
@@ -172,14 +191,20 @@ func (h *HTTPClient) AddDocument(ctx context.Context, patientID string, opts *Ad
 		}
 	}
 
-	res := &addDocumentResponse{}
+	return idempotent(ctx, h, "AddDocument", func(ctx context.Context) (string, error) {
+		res := &addDocumentResponse{}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/documents", patientID), form, res)
-	if err != nil {
-		return "", err
-	}
+		httpRes, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/documents", patientID), form, res)
+		if err != nil {
+			return "", err
+		}
+
+		if len(res.ErrorMessage) > 0 {
+			return "", rejectedError(httpRes, res.ErrorMessage)
+		}
 
-	return res.DocumentID, nil
+		return res.DocumentID, nil
+	})
 }
 
 type AddDocumentReaderOptions struct {
@@ -226,6 +251,8 @@ type AddDocumentReaderOptions struct {
 // MEDICALRECORD_PATIENTDIARY
 // MEDICALRECORD_VACCINATION
 func (h *HTTPClient) AddDocumentReader(ctx context.Context, patientID string, opts *AddDocumentReaderOptions) (string, error) {
+	ctx = contextWithAuditOperation(ctx, "AddDocumentReader")
+
 	var form *formURLEncoder
 
 	if opts != nil {
@@ -261,14 +288,20 @@ func (h *HTTPClient) AddDocumentReader(ctx context.Context, patientID string, op
 		}
 	}
 
-	res := &addDocumentResponse{}
+	return idempotent(ctx, h, "AddDocument", func(ctx context.Context) (string, error) {
+		res := &addDocumentResponse{}
 
-	_, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/documents", patientID), form, res)
-	if err != nil {
-		return "", err
-	}
+		httpRes, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/documents", patientID), form, res)
+		if err != nil {
+			return "", err
+		}
 
-	return res.DocumentID, nil
+		if len(res.ErrorMessage) > 0 {
+			return "", rejectedError(httpRes, res.ErrorMessage)
+		}
+
+		return res.DocumentID, nil
+	})
 }
 
 type AddClinicalDocumentOptions struct {
@@ -316,6 +349,8 @@ type AddClinicalDocumentResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-clinical-document#Add-clinical-document-to-patient's-chart
 func (h *HTTPClient) AddClinicalDocument(ctx context.Context, patientID string, opts *AddClinicalDocumentOptions) (*AddClinicalDocumentResponse, error) {
+	ctx = contextWithAuditOperation(ctx, "AddClinicalDocument")
+
 	var form url.Values
 
 	if opts != nil {
@@ -382,11 +417,15 @@ func (h *HTTPClient) AddClinicalDocument(ctx context.Context, patientID string,
 
 	res := &AddClinicalDocumentResponse{}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument", patientID), form, res)
+	httpRes, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument", patientID), form, res)
 	if err != nil {
 		return nil, err
 	}
 
+	if !res.Success {
+		return nil, rejectedError(httpRes, res.ErrorMessage)
+	}
+
 	return res, nil
 }
 
@@ -431,6 +470,8 @@ type AddClinicalDocumentReaderOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-clinical-document#Add-clinical-document-to-patient's-chart
 func (h *HTTPClient) AddClinicalDocumentReader(ctx context.Context, patientID string, opts *AddClinicalDocumentReaderOptions) (*AddClinicalDocumentResponse, error) {
+	ctx = contextWithAuditOperation(ctx, "AddClinicalDocumentReader")
+
 	var form *formURLEncoder
 
 	if opts != nil {
@@ -497,11 +538,15 @@ func (h *HTTPClient) AddClinicalDocumentReader(ctx context.Context, patientID st
 
 	res := &AddClinicalDocumentResponse{}
 
-	_, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument", patientID), form, res)
+	httpRes, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument", patientID), form, res)
 	if err != nil {
 		return nil, err
 	}
 
+	if !res.Success {
+		return nil, rejectedError(httpRes, res.ErrorMessage)
+	}
+
 	return res, nil
 }
 
@@ -528,6 +573,8 @@ type addPatientCaseDocumentResponse struct {
 // POST /v1/{practiceid}/patients/{patientid}/documents/patientcase
 // https://docs.athenahealth.com/api/api-ref/document-type-patient-case#Add-patient-case-document-for-a-patient
 func (h *HTTPClient) AddPatientCaseDocument(ctx context.Context, patientID string, opts *AddPatientCaseDocumentOptions) (int, error) {
+	ctx = contextWithAuditOperation(ctx, "AddPatientCaseDocument")
+
 	var form url.Values
 
 	if opts != nil {
@@ -598,14 +645,19 @@ type DeleteClinicalDocumentResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-clinical-document#Mark-patient's-clinical-document-as-deleted
 func (h *HTTPClient) DeleteClinicalDocument(ctx context.Context, patientID string, clinicalDocumentID string) (*DeleteClinicalDocumentResponse, error) {
+	ctx = contextWithAuditOperation(ctx, "DeleteClinicalDocument")
 
 	res := &DeleteClinicalDocumentResponse{}
 
-	_, err := h.Delete(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument/%s", patientID, clinicalDocumentID), nil, res)
+	httpRes, err := h.Delete(ctx, fmt.Sprintf("/patients/%s/documents/clinicaldocument/%s", patientID, clinicalDocumentID), nil, res)
 	if err != nil {
 		return nil, err
 	}
 
+	if !res.Success {
+		return nil, rejectedError(httpRes, res.ErrorMessage)
+	}
+
 	return res, nil
 }
 
@@ -694,6 +746,8 @@ type listEncounterDocumentsResponse struct {
 }
 
 func (h *HTTPClient) ListEncounterDocuments(ctx context.Context, departmentID, patientID string, opts *ListEncounterDocumentsOptions) (*ListEncounterDocumentsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListEncounterDocuments")
+
 	out := &listEncounterDocumentsResponse{}
 
 	if departmentID == "" || patientID == "" {
@@ -739,3 +793,17 @@ func (h *HTTPClient) ListEncounterDocuments(ctx context.Context, departmentID, p
 		Pagination:         makePaginationResult(out.Next, out.Previous, out.TotalCount),
 	}, nil
 }
+
+// ListEncounterDocumentsPager returns a Pager over the encounter documents returned by ListEncounterDocuments.
+func (h *HTTPClient) ListEncounterDocumentsPager(departmentID, patientID string, opts *ListEncounterDocumentsOptions) *Pager[*EncounterDocument] {
+	return newPager(h, "ListEncounterDocumentsPager", func(ctx context.Context) ([]*EncounterDocument, *PaginationResult, error) {
+		res, err := h.ListEncounterDocuments(ctx, departmentID, patientID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.EncounterDocuments, res.Pagination, nil
+	}, func(out *listEncounterDocumentsResponse) ([]*EncounterDocument, *PaginationResult) {
+		return out.EncounterDocuments, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
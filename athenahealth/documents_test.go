@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -89,6 +90,25 @@ func TestHTTPClient_AddDocument(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestHTTPClient_AddDocument_error(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := os.ReadFile("./resources/AddDocumentError.json")
+		w.Write(b)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.AddDocument(context.Background(), "123", &AddDocumentOptions{})
+	assert.ErrorIs(err, ErrValidation)
+
+	apiErr := &APIError{}
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal("/patients/:id:/documents", apiErr.Path)
+}
+
 func TestHTTPClient_AddDocumentReader(t *testing.T) {
 	assert := assert.New(t)
 
@@ -182,6 +202,26 @@ func TestHTTPClient_AddClinicalDocument(t *testing.T) {
 	assert.True(res.Success)
 }
 
+func TestHTTPClient_AddClinicalDocument_rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"errormessage":"Document could not be attached to the chart"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.AddClinicalDocument(context.Background(), "123", &AddClinicalDocumentOptions{})
+	assert.ErrorIs(err, ErrRejected)
+	assert.ErrorContains(err, "Document could not be attached to the chart")
+
+	_, err = athenaClient.AddClinicalDocumentReader(context.Background(), "123", &AddClinicalDocumentReaderOptions{
+		AttachmentContents: strings.NewReader("test attachment contents"),
+	})
+	assert.ErrorIs(err, ErrRejected)
+}
+
 func TestHTTPClient_AddPatientCaseDocument(t *testing.T) {
 	assert := assert.New(t)
 
@@ -262,6 +302,21 @@ func TestHTTPClient_DeleteClinicalDocument(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestHTTPClient_DeleteClinicalDocument_rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"errormessage":"Document cannot be deleted while it is open"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.DeleteClinicalDocument(context.Background(), "123", "101")
+	assert.ErrorIs(err, ErrRejected)
+	assert.ErrorContains(err, "Document cannot be deleted while it is open")
+}
+
 func TestHTTPClient_ListEncounterDocuments(t *testing.T) {
 	assert := assert.New(t)
 
@@ -27,6 +27,8 @@ type AddPatientDriversLicenseDocumentResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/drivers-license#Add-patient's-driver's-license-document
 func (h *HTTPClient) AddPatientDriversLicenseDocument(ctx context.Context, patientID string, opts *AddPatientDriversLicenseDocumentOptions) (*AddPatientDriversLicenseDocumentResult, error) {
+	ctx = contextWithAuditOperation(ctx, "AddPatientDriversLicenseDocument")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -41,11 +43,15 @@ func (h *HTTPClient) AddPatientDriversLicenseDocument(ctx context.Context, patie
 
 	form.Add("image", base64.StdEncoding.EncodeToString(opts.Image))
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/driverslicense", patientID), form, &out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/driverslicense", patientID), form, &out)
 	if err != nil {
 		return nil, err
 	}
 
+	if !out.Success {
+		return nil, rejectedError(res, "")
+	}
+
 	return &AddPatientDriversLicenseDocumentResult{
 		Success: out.Success,
 	}, nil
@@ -63,6 +69,8 @@ type AddPatientDriversLicenseDocumentReaderOptions struct {
 // POST /v1/{practiceid}/patients/{patientid}/driverslicense
 // https://docs.athenahealth.com/api/api-ref/drivers-license#Add-patient's-driver's-license-document
 func (h *HTTPClient) AddPatientDriversLicenseDocumentReader(ctx context.Context, patientID string, opts *AddPatientDriversLicenseDocumentReaderOptions) (*AddPatientDriversLicenseDocumentResult, error) {
+	ctx = contextWithAuditOperation(ctx, "AddPatientDriversLicenseDocumentReader")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -77,11 +85,15 @@ func (h *HTTPClient) AddPatientDriversLicenseDocumentReader(ctx context.Context,
 
 	form.AddReader("image", opts.Image)
 
-	_, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/driverslicense", patientID), form, &out)
+	res, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/driverslicense", patientID), form, &out)
 	if err != nil {
 		return nil, err
 	}
 
+	if !out.Success {
+		return nil, rejectedError(res, "")
+	}
+
 	return &AddPatientDriversLicenseDocumentResult{
 		Success: out.Success,
 	}, nil
@@ -0,0 +1,56 @@
+package athenahealth
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClient_AddPatientDriversLicenseDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	image := []byte("test image contents")
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(r.ParseForm())
+
+		assert.Equal("/patients/123/driverslicense", r.URL.Path)
+		assert.Equal(base64.StdEncoding.EncodeToString(image), r.FormValue("image"))
+		assert.Equal("2", r.FormValue("departmentid"))
+
+		w.Write([]byte(`{"success":true}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	result, err := athenaClient.AddPatientDriversLicenseDocument(context.Background(), "123", &AddPatientDriversLicenseDocumentOptions{
+		DepartmentID: "2",
+		Image:        image,
+	})
+	assert.NoError(err)
+	assert.True(result.Success)
+}
+
+func TestHTTPClient_AddPatientDriversLicenseDocument_rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.AddPatientDriversLicenseDocument(context.Background(), "123", &AddPatientDriversLicenseDocumentOptions{})
+	assert.ErrorIs(err, ErrRejected)
+
+	_, err = athenaClient.AddPatientDriversLicenseDocumentReader(context.Background(), "123", &AddPatientDriversLicenseDocumentReaderOptions{
+		Image: strings.NewReader("test image contents"),
+	})
+	assert.ErrorIs(err, ErrRejected)
+}
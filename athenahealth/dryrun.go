@@ -0,0 +1,267 @@
+package athenahealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// DryRunID is returned in place of string IDs that athena would have
+	// assigned to a write in dry-run mode.
+	DryRunID = "dry-run"
+
+	// DryRunIntID is returned in place of numeric IDs that athena would have
+	// assigned to a write in dry-run mode.
+	DryRunIntID = -1
+)
+
+// DryRunRequest is a request that was not sent because the client is in
+// dry-run mode.
+type DryRunRequest struct {
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+	XRequestID string
+}
+
+// DryRunHook is called with every request that is not sent in dry-run mode.
+type DryRunHook func(ctx context.Context, req *DryRunRequest)
+
+// WithDryRun configures whether non-GET requests are sent to athena. In
+// dry-run mode they are built and their bodies encoded as usual, then logged,
+// passed to the hook configured with WithDryRunHook and answered with a
+// synthetic 200 response without being sent. GET requests are sent as usual.
+//
+// The synthetic responses are built by the operation that made the request, so
+// write methods return these placeholders in dry-run mode:
+//
+//	AddClinicalDocument(Reader)          &AddClinicalDocumentResponse{ClinicalDocumentID: DryRunIntID, Success: true}
+//	AddDocument(Reader)                  DryRunID
+//	AddLabResultDocument(Reader)         DryRunIntID
+//	AddPatientCaseDocument               DryRunIntID
+//	AddPatientDriversLicenseDocument*    &AddPatientDriversLicenseDocumentResult{Success: true}
+//	BookAppointment                      &BookedAppointment{AppointmentID: appointmentID, PatientID: patientID}
+//	CreateAppointmentSlot                &CreateAppointmentSlotResult{AppointmentIDs: {"dry-run-1": time, ...}} (one per AppointmentTime)
+//	CreateAppointmentType                &CreateAppointmentTypeResult{AppointmentTypeID: DryRunIntID}
+//	CreateFinancialClaim                 []string{DryRunID}
+//	CreatePatient                        DryRunID
+//	CreatePatientInsurancePackage        &InsurancePackage{InsuranceID: DryRunID, InsurancePackageID: DryRunIntID}
+//	DeleteClinicalDocument               &DeleteClinicalDocumentResponse{Success: true}
+//	RescheduleAppointment                &RescheduleAppointmentResult{AppointmentID: DryRunID}
+//	UpdatePatient                        &UpdatePatientResult{PatientID: patientID}
+//	UploadPatientInsuranceCardImage*     &UploadPatientInsuranceCardImageResult{Success: true}
+//
+// Writes that only return an error return nil. Requests made directly with
+// e.g. PostForm are answered with an empty body. Idempotency keys are ignored.
+func (h *HTTPClient) WithDryRun(dryRun bool) *HTTPClient {
+	h.dryRun = dryRun
+
+	return h
+}
+
+// WithDryRunHook configures a hook that captures the requests that are not
+// sent in dry-run mode.
+func (h *HTTPClient) WithDryRunHook(hook DryRunHook) *HTTPClient {
+	h.dryRunHook = hook
+
+	return h
+}
+
+// dryRunSuccess answers writes whose responses only report success.
+func dryRunSuccess(*DryRunRequest) any {
+	return map[string]any{"success": true}
+}
+
+// dryRunResponses build the body of the synthetic response to a write in
+// dry-run mode, keyed by the operation that made it (see
+// contextWithAuditOperation). The bodies decode to the placeholders listed on
+// WithDryRun.
+var dryRunResponses = map[string]func(req *DryRunRequest) any{
+	"AddClinicalDocument": func(*DryRunRequest) any {
+		return map[string]any{"clinicaldocumentid": DryRunIntID, "success": true}
+	},
+	"AddClinicalDocumentReader": func(*DryRunRequest) any {
+		return map[string]any{"clinicaldocumentid": DryRunIntID, "success": true}
+	},
+	"AddDocument": func(*DryRunRequest) any {
+		return map[string]any{"documentid": DryRunID}
+	},
+	"AddDocumentReader": func(*DryRunRequest) any {
+		return map[string]any{"documentid": DryRunID}
+	},
+	"AddLabResultDocument": func(*DryRunRequest) any {
+		return map[string]any{"labresultid": DryRunIntID, "success": true}
+	},
+	"AddLabResultDocumentReader": func(*DryRunRequest) any {
+		return map[string]any{"labresultid": DryRunIntID, "success": true}
+	},
+	"AddPatientCaseDocument": func(*DryRunRequest) any {
+		return map[string]any{"patientcaseid": DryRunIntID}
+	},
+	"AddPatientDriversLicenseDocument":       dryRunSuccess,
+	"AddPatientDriversLicenseDocumentReader": dryRunSuccess,
+	"AppointmentCancelCheckIn":               dryRunSuccess,
+	"AppointmentCheckIn":                     dryRunSuccess,
+	"AppointmentCheckOut":                    dryRunSuccess,
+	"AppointmentStartCheckIn":                dryRunSuccess,
+	"BookAppointment": func(req *DryRunRequest) any {
+		return []map[string]any{{"appointmentid": req.pathID(), "patientid": req.form().Get("patientid")}}
+	},
+	"CreateAppointmentSlot": func(req *DryRunRequest) any {
+		appointmentIDs := map[string]string{}
+
+		for i, appointmentTime := range strings.Split(req.form().Get("appointmenttime"), ",") {
+			if appointmentTime != "" {
+				appointmentIDs[fmt.Sprintf("%s-%d", DryRunID, i+1)] = appointmentTime
+			}
+		}
+
+		return map[string]any{"appointmentids": appointmentIDs}
+	},
+	"CreateAppointmentType": func(*DryRunRequest) any {
+		return map[string]any{"appointmenttypeid": DryRunIntID}
+	},
+	"CreateFinancialClaim": func(*DryRunRequest) any {
+		return map[string]any{"claimids": []string{DryRunID}, "success": true}
+	},
+	"CreatePatient": func(*DryRunRequest) any {
+		return []map[string]any{{"patientid": DryRunID}}
+	},
+	"CreatePatientInsurancePackage": func(*DryRunRequest) any {
+		return []map[string]any{{"insuranceid": DryRunID, "insurancepackageid": DryRunIntID}}
+	},
+	"DeleteClinicalDocument":            dryRunSuccess,
+	"DeletePatientInsurancePackage":     dryRunSuccess,
+	"FreezeAppointmentSlot":             dryRunSuccess,
+	"ReactivatePatientInsurancePackage": dryRunSuccess,
+	"RescheduleAppointment": func(*DryRunRequest) any {
+		return []map[string]any{{"appointmentid": DryRunID}}
+	},
+	"UnfreezeAppointmentSlot":               dryRunSuccess,
+	"UpdateBookedAppointment":               func(*DryRunRequest) any { return updateBookedApptSuccess },
+	"UpdateHealthHistoryFormForAppointment": dryRunSuccess,
+	"UpdatePatient": func(req *DryRunRequest) any {
+		return []map[string]any{{"patientid": req.pathID()}}
+	},
+	"UpdatePatientCustomFields": dryRunSuccess,
+	"UpdatePatientInformationVerificationDetails": func(*DryRunRequest) any {
+		return []map[string]any{{"success": true}}
+	},
+	"UpdatePatientInsurancePackage": dryRunSuccess,
+	"UpdatePatientMedicationHistoryConsent": func(*DryRunRequest) any {
+		return []map[string]any{{"success": "true"}}
+	},
+	"UploadPatientInsuranceCardImage":       dryRunSuccess,
+	"UploadPatientInsuranceCardImageReader": dryRunSuccess,
+}
+
+// pathID returns the last segment of the request's path, e.g. the patient ID
+// of PUT /patients/{patientid}.
+func (r *DryRunRequest) pathID() string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return ""
+	}
+
+	return path.Base(u.Path)
+}
+
+// form returns the request's form body.
+func (r *DryRunRequest) form() url.Values {
+	form, _ := url.ParseQuery(string(r.Body))
+
+	return form
+}
+
+// dryRunRequest builds a request like attempt does, records it and returns a
+// synthetic successful response to it. The response body is built by the
+// request's operation in dryRunResponses and decoded into out.
+func (h *HTTPClient) dryRunRequest(ctx context.Context, method, reqURL string, body io.Reader, headers http.Header, xRequestID string, out interface{}) (*http.Response, error) {
+	var b []byte
+
+	if body != nil {
+		var err error
+
+		b, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+
+	req.Header.Set(XRequestIDHeaderKey, xRequestID)
+
+	h.logger.Info().
+		Str("method", method).
+		Str("url", h.logRedactor().URL(reqURL)).
+		Int("bodyBytes", len(b)).
+		Str("xRequestId", xRequestID).
+		Msg("athenahealth dry run, request not sent")
+
+	dryRunReq := &DryRunRequest{
+		Method:     method,
+		URL:        reqURL,
+		Header:     req.Header.Clone(),
+		Body:       b,
+		XRequestID: xRequestID,
+	}
+
+	if h.dryRunHook != nil {
+		h.dryRunHook(ctx, dryRunReq)
+	}
+
+	var resBody []byte
+
+	if response, ok := dryRunResponses[auditOperation(ctx)]; ok {
+		resBody, err = json.Marshal(response(dryRunReq))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+
+	if len(resBody) == 0 {
+		return res, nil
+	}
+
+	res.Header.Set("Content-Type", "application/json")
+
+	if out == nil {
+		res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+		return res, nil
+	}
+
+	err = decodeJSON(bytes.NewReader(resBody), out)
+	if err != nil {
+		return res, fmt.Errorf("Error unmarshaling response body: %s", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,395 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClient_WithDryRun(t *testing.T) {
+	assert := assert.New(t)
+
+	var methods []string
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+
+		w.Write([]byte(`[{"patientid":"1"}]`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	var captured []*DryRunRequest
+
+	athenaClient.
+		WithDryRun(true).
+		WithDryRunHook(func(ctx context.Context, req *DryRunRequest) {
+			captured = append(captured, req)
+		}).
+		WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "patient-1")
+
+	patientID, err := athenaClient.CreatePatient(ctx, &CreatePatientOptions{FirstName: "John"})
+	assert.NoError(err)
+	assert.Equal(DryRunID, patientID)
+
+	documentID, err := athenaClient.AddDocumentReader(context.Background(), "1", &AddDocumentReaderOptions{
+		AttachmentContents: strings.NewReader("hello"),
+	})
+	assert.NoError(err)
+	assert.Equal(DryRunID, documentID)
+
+	// Reads are sent.
+	_, err = athenaClient.GetPatient(context.Background(), "1", nil)
+	assert.NoError(err)
+
+	assert.Equal([]string{http.MethodGet}, methods)
+
+	assert.Len(captured, 2)
+
+	assert.Equal(http.MethodPost, captured[0].Method)
+	assert.Equal(ts.URL+"/patients", captured[0].URL)
+	assert.Equal("application/x-www-form-urlencoded", captured[0].Header.Get("Content-Type"))
+	assert.Equal(captured[0].XRequestID, captured[0].Header.Get(XRequestIDHeaderKey))
+
+	form, err := url.ParseQuery(string(captured[0].Body))
+	assert.NoError(err)
+	assert.Equal("John", form.Get("firstname"))
+
+	form, err = url.ParseQuery(string(captured[1].Body))
+	assert.NoError(err)
+	assert.Equal("aGVsbG8=", form.Get("attachmentcontents"))
+
+	// Dry-run results are not stored for idempotency keys.
+	athenaClient.WithDryRun(false)
+
+	patientID, err = athenaClient.CreatePatient(ctx, &CreatePatientOptions{})
+	assert.NoError(err)
+	assert.Equal("1", patientID)
+}
+
+func TestHTTPClient_WithDryRun_placeholders(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail("request sent in dry-run mode")
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithDryRun(true)
+
+	ctx := context.Background()
+
+	booked, err := athenaClient.BookAppointment(ctx, "1", "2", nil)
+	assert.NoError(err)
+	assert.Equal(&BookedAppointment{AppointmentID: "2", PatientID: "1"}, booked)
+
+	rescheduled, err := athenaClient.RescheduleAppointment(ctx, 2, nil)
+	assert.NoError(err)
+	assert.Equal(DryRunID, rescheduled.AppointmentID)
+
+	claimIDs, err := athenaClient.CreateFinancialClaim(ctx, &CreateClaimOptions{})
+	assert.NoError(err)
+	assert.Equal([]string{DryRunID}, claimIDs)
+
+	appointmentType, err := athenaClient.CreateAppointmentType(ctx, nil)
+	assert.NoError(err)
+	assert.Equal(DryRunIntID, appointmentType.AppointmentTypeID)
+
+	insurance, err := athenaClient.CreatePatientInsurancePackage(ctx, &CreatePatientInsurancePackageOptions{})
+	assert.NoError(err)
+	assert.Equal(DryRunID, insurance.InsuranceID)
+
+	updated, err := athenaClient.UpdatePatient(ctx, "1", nil)
+	assert.NoError(err)
+	assert.Equal("1", updated.PatientID)
+
+	assert.NoError(athenaClient.UpdateBookedAppointment(ctx, "2", &UpdateBookedAppointmentOptions{}))
+	assert.NoError(athenaClient.AppointmentCheckIn(ctx, "2"))
+	assert.NoError(athenaClient.CreateAppointmentNote(ctx, "2", &CreateAppointmentNoteOptions{NoteText: "note"}))
+}
+
+func TestHTTPClient_WithDryRun_mutatingMethods(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail("request sent in dry-run mode", "%s %s", r.Method, r.URL.Path)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	var operations []string
+
+	athenaClient.
+		WithDryRun(true).
+		WithDryRunHook(func(ctx context.Context, req *DryRunRequest) {
+			operations = append(operations, auditOperation(ctx))
+		})
+
+	var client Client = athenaClient
+
+	tests := map[string]struct {
+		call     func(ctx context.Context) (any, error)
+		expected any
+	}{
+		"AddClinicalDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddClinicalDocument(ctx, "1", &AddClinicalDocumentOptions{})
+			},
+			expected: &AddClinicalDocumentResponse{ClinicalDocumentID: DryRunIntID, Success: true},
+		},
+		"AddClinicalDocumentReader": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddClinicalDocumentReader(ctx, "1", &AddClinicalDocumentReaderOptions{AttachmentContents: strings.NewReader("hello")})
+			},
+			expected: &AddClinicalDocumentResponse{ClinicalDocumentID: DryRunIntID, Success: true},
+		},
+		"AddDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddDocument(ctx, "1", &AddDocumentOptions{})
+			},
+			expected: DryRunID,
+		},
+		"AddDocumentReader": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddDocumentReader(ctx, "1", &AddDocumentReaderOptions{AttachmentContents: strings.NewReader("hello")})
+			},
+			expected: DryRunID,
+		},
+		"AddLabResultDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddLabResultDocument(ctx, "1", "2", &AddLabResultDocumentOptions{})
+			},
+			expected: DryRunIntID,
+		},
+		"AddLabResultDocumentReader": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddLabResultDocumentReader(ctx, "1", "2", &AddLabResultDocumentReaderOptions{AttachmentContents: strings.NewReader("hello")})
+			},
+			expected: DryRunIntID,
+		},
+		"AddPatientCaseDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddPatientCaseDocument(ctx, "1", &AddPatientCaseDocumentOptions{})
+			},
+			expected: DryRunIntID,
+		},
+		"AddPatientDriversLicenseDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddPatientDriversLicenseDocument(ctx, "1", &AddPatientDriversLicenseDocumentOptions{})
+			},
+			expected: &AddPatientDriversLicenseDocumentResult{Success: true},
+		},
+		"AddPatientDriversLicenseDocumentReader": {
+			call: func(ctx context.Context) (any, error) {
+				return client.AddPatientDriversLicenseDocumentReader(ctx, "1", &AddPatientDriversLicenseDocumentReaderOptions{Image: strings.NewReader("hello")})
+			},
+			expected: &AddPatientDriversLicenseDocumentResult{Success: true},
+		},
+		"AppointmentCancelCheckIn": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.AppointmentCancelCheckIn(ctx, "2")
+			},
+		},
+		"AppointmentCheckIn": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.AppointmentCheckIn(ctx, "2")
+			},
+		},
+		"AppointmentCheckOut": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.AppointmentCheckOut(ctx, "2")
+			},
+		},
+		"AppointmentStartCheckIn": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.AppointmentStartCheckIn(ctx, "2")
+			},
+		},
+		"BookAppointment": {
+			call: func(ctx context.Context) (any, error) {
+				return client.BookAppointment(ctx, "1", "2", nil)
+			},
+			expected: &BookedAppointment{AppointmentID: "2", PatientID: "1"},
+		},
+		"CreateAppointmentNote": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.CreateAppointmentNote(ctx, "2", &CreateAppointmentNoteOptions{NoteText: "note"})
+			},
+		},
+		"CreateAppointmentSlot": {
+			call: func(ctx context.Context) (any, error) {
+				return client.CreateAppointmentSlot(ctx, &CreateAppointmentSlotOptions{AppointmentTime: []string{"09:00", "09:30"}})
+			},
+			expected: &CreateAppointmentSlotResult{AppointmentIDs: map[string]string{"dry-run-1": "09:00", "dry-run-2": "09:30"}},
+		},
+		"CreateAppointmentType": {
+			call: func(ctx context.Context) (any, error) {
+				return client.CreateAppointmentType(ctx, nil)
+			},
+			expected: &CreateAppointmentTypeResult{AppointmentTypeID: DryRunIntID},
+		},
+		"CreateFinancialClaim": {
+			call: func(ctx context.Context) (any, error) {
+				return client.CreateFinancialClaim(ctx, &CreateClaimOptions{})
+			},
+			expected: []string{DryRunID},
+		},
+		"CreatePatient": {
+			call: func(ctx context.Context) (any, error) {
+				return client.CreatePatient(ctx, &CreatePatientOptions{})
+			},
+			expected: DryRunID,
+		},
+		"CreatePatientInsurancePackage": {
+			call: func(ctx context.Context) (any, error) {
+				return client.CreatePatientInsurancePackage(ctx, &CreatePatientInsurancePackageOptions{})
+			},
+			expected: &InsurancePackage{InsuranceID: DryRunID, InsurancePackageID: DryRunIntID},
+		},
+		"DeleteAppointmentNote": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.DeleteAppointmentNote(ctx, "2", "3", &DeleteAppointmentNoteOptions{})
+			},
+		},
+		"DeleteClinicalDocument": {
+			call: func(ctx context.Context) (any, error) {
+				return client.DeleteClinicalDocument(ctx, "1", "3")
+			},
+			expected: &DeleteClinicalDocumentResponse{Success: true},
+		},
+		"DeletePatientInsurancePackage": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.DeletePatientInsurancePackage(ctx, "1", "3", "")
+			},
+		},
+		"FreezeAppointmentSlot": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.FreezeAppointmentSlot(ctx, "2", nil)
+			},
+		},
+		"ReactivatePatientInsurancePackage": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.ReactivatePatientInsurancePackage(ctx, "1", "3", nil)
+			},
+		},
+		"RescheduleAppointment": {
+			call: func(ctx context.Context) (any, error) {
+				return client.RescheduleAppointment(ctx, 2, nil)
+			},
+			expected: &RescheduleAppointmentResult{AppointmentID: DryRunID},
+		},
+		"Subscribe": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.Subscribe(ctx, "appointments", nil)
+			},
+		},
+		"UnfreezeAppointmentSlot": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UnfreezeAppointmentSlot(ctx, "2", nil)
+			},
+		},
+		"Unsubscribe": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.Unsubscribe(ctx, "appointments", nil)
+			},
+		},
+		"UpdateAppointmentNote": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdateAppointmentNote(ctx, "2", "3", &UpdateAppointmentNoteOptions{NoteText: "note"})
+			},
+		},
+		"UpdateBookedAppointment": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdateBookedAppointment(ctx, "2", &UpdateBookedAppointmentOptions{})
+			},
+		},
+		"UpdateHealthHistoryFormForAppointment": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdateHealthHistoryFormForAppointment(ctx, "2", "3", &HealthHistoryForm{})
+			},
+		},
+		"UpdatePatient": {
+			call: func(ctx context.Context) (any, error) {
+				return client.UpdatePatient(ctx, "1", nil)
+			},
+			expected: &UpdatePatientResult{PatientID: "1"},
+		},
+		"UpdatePatientCustomFields": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientCustomFields(ctx, "1", "2", nil)
+			},
+		},
+		"UpdatePatientInformationVerificationDetails": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientInformationVerificationDetails(ctx, "1", &UpdatePatientInformationVerificationDetailsOptions{})
+			},
+		},
+		"UpdatePatientInsurancePackage": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientInsurancePackage(ctx, &UpdatePatientInsurancePackageOptions{PatientID: "1", InsuranceID: "3"})
+			},
+		},
+		"UpdatePatientMedicationHistoryConsent": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientMedicationHistoryConsent(ctx, "1", &UpdatePatientMedicationHistoryConsentOptions{})
+			},
+		},
+		"UpdatePatientPhoto": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientPhoto(ctx, "1", []byte("hello"))
+			},
+		},
+		"UpdatePatientPhotoReader": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientPhotoReader(ctx, "1", strings.NewReader("hello"))
+			},
+		},
+		"UpdatePatientSocialHistory": {
+			call: func(ctx context.Context) (any, error) {
+				return nil, client.UpdatePatientSocialHistory(ctx, "1", &UpdatePatientSocialHistoryOptions{})
+			},
+		},
+		"UploadPatientInsuranceCardImage": {
+			call: func(ctx context.Context) (any, error) {
+				return client.UploadPatientInsuranceCardImage(ctx, "1", "3", &UploadPatientInsuranceCardImageOptions{})
+			},
+			expected: &UploadPatientInsuranceCardImageResult{Success: true},
+		},
+		"UploadPatientInsuranceCardImageReader": {
+			call: func(ctx context.Context) (any, error) {
+				return client.UploadPatientInsuranceCardImageReader(ctx, "1", "3", &UploadPatientInsuranceCardImageReaderOptions{Image: strings.NewReader("hello")})
+			},
+			expected: &UploadPatientInsuranceCardImageResult{Success: true},
+		},
+	}
+
+	// Every operation with a synthetic response is covered.
+	for operation := range dryRunResponses {
+		assert.Contains(tests, operation)
+	}
+
+	for name, tt := range tests {
+		operations = nil
+
+		out, err := tt.call(context.Background())
+		assert.NoError(err, name)
+
+		if tt.expected != nil {
+			assert.Equal(tt.expected, out, name)
+		}
+
+		assert.Equal([]string{name}, operations)
+	}
+}
@@ -0,0 +1,87 @@
+package athenahealth
+
+import (
+	"strings"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+)
+
+// EndpointGroup names a group of related athena endpoints. Circuit breakers
+// can be scoped per group so that one degraded area of athena doesn't stop
+// requests to the others.
+type EndpointGroup string
+
+const (
+	EndpointGroupDocuments  EndpointGroup = "documents"
+	EndpointGroupScheduling EndpointGroup = "scheduling"
+	EndpointGroupChart      EndpointGroup = "chart"
+	EndpointGroupPatients   EndpointGroup = "patients"
+	EndpointGroupBilling    EndpointGroup = "billing"
+	EndpointGroupPractice   EndpointGroup = "practice"
+	EndpointGroupOther      EndpointGroup = "other"
+)
+
+// EndpointGrouper returns the group of the endpoint that a request to path
+// (relative to the practice, e.g. /patients/1) with method is sent to.
+type EndpointGrouper func(method, path string) EndpointGroup
+
+// chartSegments are path segments of clinical chart endpoints.
+var chartSegments = []string{
+	"allergies",
+	"chart",
+	"encounter",
+	"encounters",
+	"labresults",
+	"medications",
+	"physicalexam",
+	"prescriptions",
+	"problems",
+	"socialhistory",
+}
+
+// DefaultEndpointGroup groups endpoints by the area of athena they belong to.
+func DefaultEndpointGroup(method, path string) EndpointGroup {
+	segments := strings.Split(strings.Trim(stats.CleanPath(path), "/"), "/")
+
+	if len(segments) == 0 {
+		return EndpointGroupOther
+	}
+
+	for _, segment := range segments {
+		if segment == "documents" {
+			return EndpointGroupDocuments
+		}
+	}
+
+	for _, segment := range segments {
+		for _, chartSegment := range chartSegments {
+			if segment == chartSegment {
+				return EndpointGroupChart
+			}
+		}
+	}
+
+	switch segments[0] {
+	case "appointments":
+		return EndpointGroupScheduling
+
+	case "patients":
+		return EndpointGroupPatients
+
+	case "claims":
+		return EndpointGroupBilling
+
+	case "departments", "providers", "customfields", "misc":
+		return EndpointGroupPractice
+	}
+
+	return EndpointGroupOther
+}
+
+// WithEndpointGrouper configures how requests are assigned to endpoint groups.
+// By default DefaultEndpointGroup is used.
+func (h *HTTPClient) WithEndpointGrouper(grouper EndpointGrouper) *HTTPClient {
+	h.endpointGrouper = grouper
+
+	return h
+}
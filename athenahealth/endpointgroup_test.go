@@ -0,0 +1,47 @@
+package athenahealth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultEndpointGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]EndpointGroup{
+		"/patients/1/documents/admin":       EndpointGroupDocuments,
+		"/patients/1/documents/labresult/2": EndpointGroupDocuments,
+		"/chart/1/socialhistory":            EndpointGroupChart,
+		"/chart/encounter/1/physicalexam":   EndpointGroupChart,
+		"/patients/1/problems":              EndpointGroupChart,
+		"/labresults/changed":               EndpointGroupChart,
+		"/appointments/open":                EndpointGroupScheduling,
+		"/appointments/1/checkin":           EndpointGroupScheduling,
+		"/patients/1":                       EndpointGroupPatients,
+		"/patients/1/insurances":            EndpointGroupPatients,
+		"/claims":                           EndpointGroupBilling,
+		"/departments":                      EndpointGroupPractice,
+		"/customfields":                     EndpointGroupPractice,
+		"/unknown":                          EndpointGroupOther,
+		"":                                  EndpointGroupOther,
+	}
+
+	for path, group := range cases {
+		assert.Equal(group, DefaultEndpointGroup(http.MethodGet, path), path)
+	}
+}
+
+func TestHTTPClient_WithEndpointGrouper(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testClient(nil)
+	defer ts.Close()
+
+	athenaClient.WithEndpointGrouper(func(method, path string) EndpointGroup {
+		return EndpointGroup(method)
+	})
+
+	assert.Equal(EndpointGroup(http.MethodPost), athenaClient.endpointGrouper(http.MethodPost, "/patients"))
+}
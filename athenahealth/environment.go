@@ -0,0 +1,106 @@
+package athenahealth
+
+import (
+	"strings"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
+)
+
+// Environment describes an athenahealth API environment: where requests and
+// token requests are sent, and the defaults that apply to it.
+type Environment struct {
+	Name string
+
+	// BaseURL is the base URL of API requests, without the practice ID, e.g.
+	// https://api.platform.athenahealth.com/v1/. It is required.
+	BaseURL string
+	// AuthURL is the OAuth token URL used by the default TokenProvider. It is
+	// required unless another TokenProvider is configured first.
+	AuthURL string
+	// Scope is the OAuth scope requested by the default TokenProvider. If
+	// empty, tokenprovider.DefaultScope is requested.
+	Scope string
+
+	// RateLimit is the environment's rate limit in requests per second. It is
+	// passed to the RateLimiter, which uses it unless it was constructed with
+	// a rate, e.g. ratelimiter.NewMemory(0, 0).
+	RateLimit int
+
+	// Preview is passed to the RateLimiter. Logs are not redacted by
+	// default in preview environments.
+	Preview bool
+}
+
+var (
+	// PreviewEnvironment is athena's preview (sandbox) environment.
+	PreviewEnvironment = Environment{
+		Name:      "preview",
+		BaseURL:   PreviewBaseURL,
+		AuthURL:   tokenprovider.PreviewAuthURL,
+		Scope:     tokenprovider.DefaultScope,
+		RateLimit: 5,
+		Preview:   true,
+	}
+
+	// ProdEnvironment is athena's production environment.
+	ProdEnvironment = Environment{
+		Name:      "prod",
+		BaseURL:   ProdBaseURL,
+		AuthURL:   tokenprovider.ProdAuthURL,
+		Scope:     tokenprovider.DefaultScope,
+		RateLimit: 100,
+		Preview:   false,
+	}
+)
+
+// WithEnvironment configures the environment requests are sent to. Use
+// PreviewEnvironment or ProdEnvironment, or a custom Environment to send
+// requests through a proxy or to a stand-in server. By default
+// PreviewEnvironment is used.
+//
+// The default TokenProvider is replaced with one for env. A TokenProvider set
+// with WithTokenProvider is kept. WithEnvironment panics if env has no BaseURL,
+// or no AuthURL for the default TokenProvider.
+func (h *HTTPClient) WithEnvironment(env Environment) *HTTPClient {
+	if len(env.BaseURL) == 0 {
+		panic("env.BaseURL is empty")
+	}
+
+	_, defaultTokenProvider := h.tokenProvider.(*tokenprovider.Default)
+	if defaultTokenProvider && len(env.AuthURL) == 0 {
+		panic("env.AuthURL is empty")
+	}
+
+	if !strings.HasSuffix(env.BaseURL, "/") {
+		env.BaseURL += "/"
+	}
+
+	h.environment = env
+	h.setBaseURL()
+
+	if defaultTokenProvider {
+		h.tokenProvider = h.defaultTokenProvider()
+	}
+
+	return h
+}
+
+// Environment returns the environment requests are sent to.
+func (h *HTTPClient) Environment() Environment {
+	return h.environment
+}
+
+func (h *HTTPClient) defaultTokenProvider() *tokenprovider.Default {
+	p := tokenprovider.NewDefault(h.httpClient, h.clientID, h.secret, h.environment.Preview).
+		WithAuthURL(h.environment.AuthURL)
+
+	if len(h.environment.Scope) > 0 {
+		p.WithScope(h.environment.Scope)
+	}
+
+	if h.tracerProvider != nil {
+		p.WithTracerProvider(h.tracerProvider)
+	}
+
+	return p
+}
@@ -0,0 +1,115 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClient_WithEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	var paths []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		if r.URL.Path == "/oauth/token" {
+			assert.Equal("custom/scope", r.FormValue("scope"))
+
+			w.Write([]byte(`{"access_token":"token","expires_in":"60"}`))
+			return
+		}
+
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	env := Environment{
+		Name:    "proxy",
+		BaseURL: ts.URL + "/athena/v1",
+		AuthURL: ts.URL + "/oauth/token",
+		Scope:   "custom/scope",
+	}
+
+	athenaClient := NewHTTPClient(ts.Client(), testPracticeID, "", "").
+		WithTokenCacher(tokencacher.NewDefault()).
+		WithEnvironment(env)
+
+	assert.Equal("proxy", athenaClient.Environment().Name)
+	assert.Equal(ts.URL+"/athena/v1/", athenaClient.Environment().BaseURL)
+
+	_, err := athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal([]string{"/oauth/token", "/athena/v1/" + testPracticeID + "/patients/1"}, paths)
+}
+
+func TestHTTPClient_WithEnvironment_tokenProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, testPracticeID, "", "")
+	assert.IsType(&tokenprovider.Default{}, athenaClient.tokenProvider)
+
+	tokenProvider := &testTokenProvider{}
+	athenaClient.WithTokenProvider(tokenProvider).WithEnvironment(ProdEnvironment)
+
+	assert.Same(tokenProvider, athenaClient.tokenProvider)
+	assert.Equal(ProdBaseURL+testPracticeID, athenaClient.baseURL)
+}
+
+func TestHTTPClient_WithEnvironment_incomplete(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, testPracticeID, "", "")
+
+	assert.PanicsWithValue("env.BaseURL is empty", func() {
+		athenaClient.WithEnvironment(Environment{AuthURL: tokenprovider.PreviewAuthURL})
+	})
+
+	assert.PanicsWithValue("env.AuthURL is empty", func() {
+		athenaClient.WithEnvironment(Environment{BaseURL: PreviewBaseURL})
+	})
+
+	assert.Equal(PreviewEnvironment, athenaClient.Environment())
+
+	// AuthURL is only needed by the default TokenProvider.
+	athenaClient.WithTokenProvider(&testTokenProvider{}).WithEnvironment(Environment{BaseURL: PreviewBaseURL})
+	assert.Equal(PreviewBaseURL+testPracticeID, athenaClient.baseURL)
+}
+
+func TestHTTPClient_WithEnvironment_rateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := 0
+
+	athenaClient, ts := testClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Write([]byte(`{}`))
+	})
+	defer ts.Close()
+
+	// The limiter has no rate of its own, so it uses the environment's.
+	athenaClient.WithEnvironment(Environment{BaseURL: ts.URL, AuthURL: ts.URL, RateLimit: 2}).
+		WithRequestRateLimiter(ratelimiter.NewMemory(0, 0))
+	athenaClient.baseURL = ts.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	for range 3 {
+		athenaClient.Get(ctx, "/departments", nil, nil)
+	}
+
+	assert.Equal(2, requests)
+}
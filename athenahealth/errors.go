@@ -1,5 +1,166 @@
 package athenahealth
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 
-var ErrNotFound = errors.New("not found")
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+)
+
+// Errors returned by the athenahealth API, wrapped in an *APIError. Use
+// errors.Is to test for them.
+var (
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when athena rejects the token, even after it
+	// has been refreshed.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the client is not allowed to access the
+	// practice or endpoint.
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited is returned when athena responds with 429.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation is returned when athena rejects the request's parameters.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict is returned when the request conflicts with existing data,
+	// e.g. a duplicate record or a slot that is already frozen.
+	ErrConflict = errors.New("conflict")
+	// ErrUpstreamUnavailable is returned when athena responds with a 5xx, and
+	// when a request gets no response at all: it could not be sent, e.g. the
+	// connection failed, or the circuit breaker, bulkhead or rate limiter did
+	// not let it through in time.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// ErrRejected is returned when athena responds with success but reports
+	// that it did not perform the request, e.g. "success": false.
+	ErrRejected = errors.New("rejected")
+)
+
+// statusError returns the sentinel for an error response. message is athena's
+// description of the error.
+func statusError(statusCode int, message string) error {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+
+	case statusCode == http.StatusForbidden:
+		return ErrForbidden
+
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusUnprocessableEntity:
+		if isConflictMessage(message) {
+			return ErrConflict
+		}
+
+		return ErrValidation
+
+	case statusCode >= http.StatusInternalServerError:
+		return ErrUpstreamUnavailable
+	}
+
+	return nil
+}
+
+// rejectionError returns the sentinel for a successful response whose body
+// reports failure. athena reports validation failures and conflicts this way
+// too, so message is used to tell them apart from business-rule rejections.
+func rejectionError(message string) error {
+	if isConflictMessage(message) {
+		return ErrConflict
+	}
+
+	if isValidationMessage(message) {
+		return ErrValidation
+	}
+
+	return ErrRejected
+}
+
+func isConflictMessage(message string) bool {
+	message = strings.ToLower(message)
+
+	return strings.Contains(message, "already") || strings.Contains(message, "duplicate")
+}
+
+func isValidationMessage(message string) bool {
+	message = strings.ToLower(message)
+
+	return strings.Contains(message, "invalid") || strings.Contains(message, "required") || strings.Contains(message, "missing")
+}
+
+// newAPIError returns an APIError for res, identified by the request that res
+// is a response to.
+func newAPIError(res *http.Response) *APIError {
+	err := &APIError{
+		HTTPResponse: res,
+	}
+
+	if res != nil && res.Request != nil {
+		info := requestInfoFromRequest(res.Request)
+
+		err.XRequestID = info.xRequestID
+		if len(err.XRequestID) == 0 {
+			err.XRequestID = res.Request.Header.Get(XRequestIDHeaderKey)
+		}
+		err.Method = res.Request.Method
+		err.Path = stats.CleanPath(info.path)
+	}
+
+	return err
+}
+
+// unavailableError is the Err of an APIError for a request that got no
+// response. It matches ErrUpstreamUnavailable as well as the error that
+// prevented the response, e.g. a *CircuitOpenError or *url.Error.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUpstreamUnavailable, e.err)
+}
+
+func (e *unavailableError) Unwrap() []error {
+	return []error{ErrUpstreamUnavailable, e.err}
+}
+
+// transportError returns the error for req, which got no response because of
+// err. Errors that are already an *APIError are returned as is.
+func transportError(req *http.Request, err error) error {
+	if errors.As(err, new(*APIError)) {
+		return err
+	}
+
+	info := requestInfoFromRequest(req)
+
+	return &APIError{
+		Err:        &unavailableError{err: err},
+		XRequestID: info.xRequestID,
+		Method:     req.Method,
+		Path:       stats.CleanPath(info.path),
+	}
+}
+
+// rejectedError returns the error for res, a successful response whose body
+// reports that athena did not perform the request. message is athena's reason,
+// if it gave one.
+func rejectedError(res *http.Response, message string) error {
+	err := newAPIError(res)
+	err.AthenaError = message
+	err.Err = rejectionError(message)
+
+	if len(message) == 0 {
+		err.AthenaError = "request rejected"
+	}
+
+	return err
+}
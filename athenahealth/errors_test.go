@@ -0,0 +1,129 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_statusError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(ErrUnauthorized, statusError(http.StatusUnauthorized, ""))
+	assert.Equal(ErrForbidden, statusError(http.StatusForbidden, ""))
+	assert.Equal(ErrNotFound, statusError(http.StatusNotFound, ""))
+	assert.Equal(ErrConflict, statusError(http.StatusConflict, ""))
+	assert.Equal(ErrRateLimited, statusError(http.StatusTooManyRequests, ""))
+	assert.Equal(ErrValidation, statusError(http.StatusBadRequest, "Invalid departmentid"))
+	assert.Equal(ErrConflict, statusError(http.StatusBadRequest, "Patient already exists"))
+	assert.Equal(ErrUpstreamUnavailable, statusError(http.StatusServiceUnavailable, ""))
+	assert.Nil(statusError(http.StatusTeapot, ""))
+}
+
+func Test_rejectionError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(ErrConflict, rejectionError("Duplicate appointment"))
+	assert.Equal(ErrValidation, rejectionError("departmentid is required"))
+	assert.Equal(ErrRejected, rejectionError("Slot is not bookable"))
+	assert.Equal(ErrRejected, rejectionError(""))
+}
+
+func TestHTTPClient_request_typed_errors(t *testing.T) {
+	assert := assert.New(t)
+
+	statusCode := http.StatusForbidden
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		w.Write([]byte(`{"error":"Access denied"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.ErrorIs(err, ErrForbidden)
+
+	apiErr := &APIError{}
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal(http.MethodGet, apiErr.Method)
+	assert.Equal("/patients/:id:", apiErr.Path)
+	assert.NotEmpty(apiErr.XRequestID)
+	assert.Contains(apiErr.Error(), apiErr.XRequestID)
+
+	statusCode = http.StatusBadGateway
+
+	_, err = athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+}
+
+func TestHTTPClient_request_unavailable_errors(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	assertUnavailable := func(err error) {
+		assert.ErrorIs(err, ErrUpstreamUnavailable)
+
+		apiErr := &APIError{}
+		if assert.ErrorAs(err, &apiErr) {
+			assert.Equal(http.MethodGet, apiErr.Method)
+			assert.Equal("/patients/:id:", apiErr.Path)
+			assert.NotEmpty(apiErr.XRequestID)
+			assert.Contains(apiErr.Error(), apiErr.XRequestID)
+		}
+	}
+
+	// The circuit breaker is open.
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	cb, _ := testCircuitBreaker(CircuitBreakerScopeClient)
+	athenaClient.WithCircuitBreaker(cb)
+
+	for i := 0; i < 4; i++ {
+		athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	}
+
+	_, err := athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.ErrorIs(err, ErrCircuitOpen)
+	assertUnavailable(err)
+
+	// The rate limiter doesn't let the request through before its deadline.
+	athenaClient, ts = testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithRequestRateLimiter(&testRequestRateLimiter{
+		AllowRequestFunc: func(req ratelimiter.Request) (time.Duration, error) {
+			return time.Hour, ratelimiter.ErrRateExceeded
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = athenaClient.Get(ctx, "/patients/1", nil, nil)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assertUnavailable(err)
+
+	// athena can't be reached.
+	athenaClient, ts = testClient(h)
+	ts.Close()
+
+	athenaClient.WithRetryPolicy(testRetryPolicy(1))
+
+	_, err = athenaClient.Get(context.Background(), "/patients/1?firstname=Jane", nil, nil)
+	assert.ErrorAs(err, new(*url.Error))
+	assertUnavailable(err)
+
+	redacted := NewRedactor().Error(err)
+	assert.ErrorIs(redacted, ErrUpstreamUnavailable)
+	assert.NotContains(redacted.Error(), "Jane")
+}
@@ -30,9 +30,79 @@ func (f *formURLEncoder) AddInt(key string, value int) {
 }
 
 func (f *formURLEncoder) AddReader(key string, value io.Reader) {
+	// Remember where seekable readers start so the form can be encoded again
+	// if the request needs to be retried.
+	if rs, ok := value.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err == nil {
+			value = &rewindableReader{ReadSeeker: rs, offset: offset}
+		}
+	}
+
 	f.entries[key] = append(f.entries[key], value)
 }
 
+// rewind seeks every reader value back to where it started. It returns
+// errBodyNotReplayable if any reader value cannot be rewound.
+func (f *formURLEncoder) rewind() error {
+	for _, vals := range f.entries {
+		for _, val := range vals {
+			switch v := val.(type) {
+			case *rewindableReader:
+				_, err := v.Seek(v.offset, io.SeekStart)
+				if err != nil {
+					return err
+				}
+
+			case io.Reader:
+				return errBodyNotReplayable
+			}
+		}
+	}
+
+	return nil
+}
+
+// scalarValues returns the string and int values of the form.
+func (f *formURLEncoder) scalarValues() url.Values {
+	v := url.Values{}
+
+	for key, vals := range f.entries {
+		for _, val := range vals {
+			switch val := val.(type) {
+			case string:
+				v.Add(key, val)
+
+			case int:
+				v.Add(key, strconv.Itoa(val))
+			}
+		}
+	}
+
+	return v
+}
+
+// newReader returns a reader that streams the encoded form.
+func (f *formURLEncoder) newReader(ctx context.Context) *formURLEncoderReader {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		err := f.Encode(ctx, pw)
+		//nolint
+		pw.CloseWithError(err)
+		close(done)
+	}()
+
+	return &formURLEncoderReader{
+		PipeReader: pr,
+
+		ctx:  ctx,
+		fue:  f,
+		done: done,
+	}
+}
+
 // Encode encodes the values into “URL encoded” form
 // ("bar=baz&foo=quux") sorted by key.
 func (f *formURLEncoder) Encode(ctx context.Context, w io.Writer) error {
@@ -108,6 +178,35 @@ func (f *formURLEncoder) Encode(ctx context.Context, w io.Writer) error {
 	return nil
 }
 
+type rewindableReader struct {
+	io.ReadSeeker
+	offset int64
+}
+
+// formURLEncoderReader is the request body produced by PostFormReader. It
+// keeps a reference to its encoder so the body can be rebuilt on retry.
+type formURLEncoderReader struct {
+	*io.PipeReader
+
+	ctx  context.Context
+	fue  *formURLEncoder
+	done chan struct{}
+}
+
+// replay stops the current encoding, rewinds the encoder's readers and
+// returns a fresh reader for the same form.
+func (r *formURLEncoderReader) replay() (*formURLEncoderReader, error) {
+	r.PipeReader.Close()
+	<-r.done
+
+	err := r.fue.rewind()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.fue.newReader(r.ctx), nil
+}
+
 type readerFunc func(p []byte) (n int, err error)
 
 func (rf readerFunc) Read(p []byte) (n int, err error) { return rf(p) }
@@ -300,6 +300,8 @@ func (h *HealthHistoryForm) UnmarshalJSON(data []byte) error {
 // GET /v1/{practiceid}/appointments/{appointmentid}/healthhistoryforms/{formid}
 // https://docs.athenahealth.com/api/api-ref/appointment-health-history-form-documents#Get-specific-health-history-forms-for-given-appointment
 func (h *HTTPClient) GetHealthHistoryFormForAppointment(ctx context.Context, appointmentID, formID string) (*HealthHistoryForm, error) {
+	ctx = contextWithAuditOperation(ctx, "GetHealthHistoryFormForAppointment")
+
 	hhf := &HealthHistoryForm{}
 
 	_, err := h.Get(ctx, fmt.Sprintf("/appointments/%s/healthhistoryforms/%s", url.QueryEscape(appointmentID), url.QueryEscape(formID)), nil, hhf)
@@ -314,6 +316,8 @@ func (h *HTTPClient) GetHealthHistoryFormForAppointment(ctx context.Context, app
 // PUT /v1/{practiceid}/appointments/{appointmentid}/healthhistoryforms/{formid}
 // https://docs.athenahealth.com/api/api-ref/appointment-health-history-form-documents#Update-specific-health-history-forms-for-given-appointment
 func (h *HTTPClient) UpdateHealthHistoryFormForAppointment(ctx context.Context, appointmentID, formID string, form *HealthHistoryForm) error {
+	ctx = contextWithAuditOperation(ctx, "UpdateHealthHistoryFormForAppointment")
+
 	if form == nil {
 		return errors.New("form is nil")
 	}
@@ -354,13 +358,13 @@ func (h *HTTPClient) UpdateHealthHistoryFormForAppointment(ctx context.Context,
 
 	out := &ErrorMessageResponse{}
 
-	_, err = h.PutForm(ctx, fmt.Sprintf("/appointments/%s/healthhistoryforms/%s", url.QueryEscape(appointmentID), url.QueryEscape(formID)), payload, out)
+	res, err := h.PutForm(ctx, fmt.Sprintf("/appointments/%s/healthhistoryforms/%s", url.QueryEscape(appointmentID), url.QueryEscape(formID)), payload, out)
 	if err != nil {
 		return fmt.Errorf("updating health history form for appointment: %w", err)
 	}
 
 	if !out.Success {
-		return fmt.Errorf("error returned from athena: %s", out.Message)
+		return fmt.Errorf("updating health history form for appointment: %w", rejectedError(res, out.Message))
 	}
 
 	return nil
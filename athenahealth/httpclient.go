@@ -17,9 +17,12 @@ import (
 	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
 	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
 	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
-	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -37,6 +40,9 @@ const (
 
 	// XRequestIDHeaderKey https://docs.athenahealth.com/api/guides/best-practices
 	XRequestIDHeaderKey = "X-Request-Id"
+
+	// tokenFlightKey is the singleflight key shared by concurrent token fetches.
+	tokenFlightKey = "token"
 )
 
 type HTTPClient struct {
@@ -45,24 +51,49 @@ type HTTPClient struct {
 	practiceID     string
 	clientID       string
 	secret         string
-	preview        bool
+	environment    Environment
+	apiURL         string
 	baseURL        string
 	requestTimeout time.Duration
 
 	tokenProvider TokenProvider
 	tokenCacher   TokenCacher
-	rateLimiter   RateLimiter
-	stats         Stats
+	rateLimiter   RequestRateLimiter
+	stats         StatsRecorder
 	logger        *zerolog.Logger
+	retryPolicy   *RetryPolicy
+	middleware    []Middleware
+
+	tracer         trace.Tracer
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+
+	redactor           *Redactor
+	redactorConfigured bool
+
+	endpointGrouper EndpointGrouper
+	circuitBreaker  *CircuitBreaker
+	bulkhead        *Bulkhead
+
+	idempotencyStore IdempotencyStore
+	idempotencyTTL   time.Duration
 
-	requestLock sync.Mutex
+	dryRun     bool
+	dryRunHook DryRunHook
+
+	auditor Auditor
+
+	tokenGroup *singleflight.Group
+	practices  *practiceRegistry
+
+	// closers are the clients created for h by Config.NewHTTPClient, which
+	// Close closes.
+	closers []io.Closer
 }
 
 var _ Client = (*HTTPClient)(nil)
 
 func NewHTTPClient(httpClient *http.Client, practiceID, clientID, secret string) *HTTPClient {
-	preview := true
-
 	noplogger := zerolog.Nop()
 
 	c := &HTTPClient{
@@ -71,27 +102,47 @@ func NewHTTPClient(httpClient *http.Client, practiceID, clientID, secret string)
 		practiceID:     practiceID,
 		clientID:       clientID,
 		secret:         secret,
-		preview:        preview,
+		environment:    PreviewEnvironment,
 		requestTimeout: defaultRequestTimeout,
 
-		tokenProvider: tokenprovider.NewDefault(httpClient, clientID, secret, preview),
-		tokenCacher:   tokencacher.NewDefault(),
-		rateLimiter:   ratelimiter.NewDefault(),
-		stats:         stats.NewDefault(),
-		logger:        &noplogger,
+		tokenCacher: tokencacher.NewDefault(),
+		rateLimiter: ratelimiter.NewDefault(),
+		stats:       stats.NewDefault(),
+		logger:      &noplogger,
+		retryPolicy: noRetryPolicy,
+
+		tracer:     defaultTracer(),
+		propagator: otel.GetTextMapPropagator(),
+
+		endpointGrouper: DefaultEndpointGroup,
+
+		tokenGroup: &singleflight.Group{},
+		practices:  &practiceRegistry{},
 	}
 
+	c.tokenProvider = c.defaultTokenProvider()
 	c.setBaseURL()
 
 	return c
 }
 
-func (h *HTTPClient) setBaseURL() {
-	if h.preview {
-		h.baseURL = fmt.Sprintf("%s%s", PreviewBaseURL, h.practiceID)
-	} else {
-		h.baseURL = fmt.Sprintf("%s%s", ProdBaseURL, h.practiceID)
+// Close closes the Redis and statsd clients created by Config.NewHTTPClient.
+// Clients built with NewHTTPClient have nothing to close; their dependencies
+// are owned by the caller. Views returned by ForPractice share the clients, so
+// only close the client they were created from, once it is no longer used.
+func (h *HTTPClient) Close() error {
+	var errs []error
+
+	for _, closer := range h.closers {
+		errs = append(errs, closer.Close())
 	}
+
+	return errors.Join(errs...)
+}
+
+func (h *HTTPClient) setBaseURL() {
+	h.apiURL = h.environment.BaseURL
+	h.baseURL = fmt.Sprintf("%s%s", h.apiURL, h.practiceID)
 }
 
 func (h *HTTPClient) request(ctx context.Context, method, path string, body io.Reader, headers http.Header, out interface{}) (*http.Response, error) {
@@ -101,11 +152,9 @@ func (h *HTTPClient) request(ctx context.Context, method, path string, body io.R
 		defer cancel()
 	}
 
-	var token string
-	var err error
-	var expiresAt time.Time
-
-	h.requestLock.Lock()
+	if practiceID, ok := PracticeIDFromContext(ctx); ok && practiceID != h.practiceID {
+		return h.ForPractice(practiceID).request(ctx, method, path, body, headers, out)
+	}
 
 	if !strings.HasPrefix(path, "/") {
 		path = fmt.Sprintf("/%s", path)
@@ -113,63 +162,123 @@ func (h *HTTPClient) request(ctx context.Context, method, path string, body io.R
 
 	reqURL := fmt.Sprintf("%s%s", h.baseURL, path)
 
-	retryAfter, err := h.rateLimiter.Allowed(ctx, h.preview)
-	if err != nil {
-		h.requestLock.Unlock()
+	xRequestID := uuid.NewString()
+
+	if h.dryRun && method != http.MethodGet {
+		res, err := h.dryRunRequest(ctx, method, reqURL, body, headers, xRequestID, out)
+
+		h.audit(ctx, method, path, xRequestID, true, res, err)
+
+		return res, err
+	}
+
+	ctx, span := h.startSpan(ctx, method, path, xRequestID)
+	rt := &requestTrace{}
+
+	res, err := h.requestWithRetries(ctx, method, path, reqURL, newReplayableBody(body), headers, xRequestID, rt, out)
+
+	h.endSpan(span, rt, res, err)
+
+	h.audit(ctx, method, path, xRequestID, false, res, err)
+
+	return res, err
+}
+
+// requestWithRetries makes attempts at a request until one succeeds or the
+// retry policy gives up.
+func (h *HTTPClient) requestWithRetries(ctx context.Context, method, path, reqURL string, body io.Reader, headers http.Header, xRequestID string, rt *requestTrace, out interface{}) (*http.Response, error) {
+	tokenRefreshed := false
+
+	// body is replaced on replay, so close whichever body is current.
+	defer func() {
+		closeBody(body)
+	}()
+
+	for attempt := 1; ; attempt++ {
+		info := &requestInfo{
+			path:       path,
+			xRequestID: xRequestID,
+			attempt:    attempt,
+		}
+
+		res, err := h.attempt(ctx, method, reqURL, body, headers, info, out)
+
+		rt.rateLimitWait += info.rateLimitWait
+
+		// athena may reject a token that we still consider valid (e.g. it was
+		// revoked or rotated by another process). Refresh it and replay the
+		// request once.
+		if res != nil && res.StatusCode == http.StatusUnauthorized && !tokenRefreshed {
+			tokenRefreshed = true
+
+			replayed, replayErr := replayBody(body)
+			if replayErr != nil {
+				return res, err
+			}
+			body = replayed
 
-		if errors.Is(err, ratelimiter.ErrRateExceeded) {
 			h.logger.Info().
 				Str("method", method).
-				Str("url", reqURL).
-				Err(err).
-				Msg("athenahealth API request rate limited")
+				Str("url", h.logRedactor().URL(reqURL)).
+				Str("xRequestId", xRequestID).
+				Msg("athenahealth API token rejected, refreshing")
 
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("waiting for rate limit retry interval: %w", ctx.Err())
-
-			case <-time.After(retryAfter):
-				return h.request(ctx, method, path, body, headers, out)
+			refreshErr := h.refreshToken(ctx, info.token)
+			if refreshErr != nil {
+				return res, refreshErr
 			}
-		}
 
-		return nil, err
-	}
+			// The replay doesn't count against the retry policy.
+			attempt--
+			rt.resends++
 
-	token, err = h.tokenCacher.Get(ctx)
-	if err != nil {
-		if !errors.Is(err, tokencacher.ErrTokenNotExist) && !errors.Is(err, tokencacher.ErrTokenExpired) {
-			h.requestLock.Unlock()
-			return nil, err
+			continue
 		}
 
-		token, expiresAt, err = h.tokenProvider.Provide(ctx)
-		if err != nil {
-			h.requestLock.Unlock()
-			return nil, err
+		delay, retry := h.retryPolicy.retryDelay(ctx, method, attempt, res, err)
+		if !retry {
+			return res, err
 		}
 
-		// Remove 1 minute from the expiration time to create a buffer to see
-		// if it resolves intermittent 401s.
-		err = h.tokenCacher.Set(context.Background(), token, expiresAt.Add(-1*time.Minute))
-		if err != nil {
-			h.requestLock.Unlock()
-			return nil, err
+		replayed, replayErr := replayBody(body)
+		if replayErr != nil {
+			return res, err
 		}
-	}
+		body = replayed
+
+		rt.resends++
+
+		h.logger.Info().
+			Str("method", method).
+			Str("url", h.logRedactor().URL(reqURL)).
+			Str("xRequestId", xRequestID).
+			Int("attempt", attempt).
+			Str("delay", delay.String()).
+			Err(h.logRedactor().Error(err)).
+			Msg("athenahealth API request retrying")
+
+		select {
+		case <-ctx.Done():
+			return res, err
 
-	h.requestLock.Unlock()
+		case <-time.After(delay):
+		}
+	}
+}
 
+// attempt makes a single request to the athenahealth API.
+func (h *HTTPClient) attempt(ctx context.Context, method, reqURL string, body io.Reader, headers http.Header, info *requestInfo, out interface{}) (*http.Response, error) {
 	if body != nil {
-		body = newSizeRecordingReader(body)
+		info.body = newSizeRecordingReader(body)
+		body = info.body
 	}
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	req, err := http.NewRequestWithContext(contextWithRequestInfo(ctx, info), method, reqURL, body)
 	if err != nil {
 		return nil, err
 	}
 
 	if headers != nil {
-		req.Header = headers
+		req.Header = headers.Clone()
 
 		// Go's http lib honors Content-Length on the Request struct above the header
 		if cl := req.Header.Get("Content-Length"); len(cl) > 0 {
@@ -182,98 +291,171 @@ func (h *HTTPClient) request(ctx context.Context, method, path string, body io.R
 		}
 	}
 
-	xRequestID := uuid.NewString()
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	req.Header.Add("User-Agent", userAgent)
-	req.Header.Set(XRequestIDHeaderKey, xRequestID)
 
-	h.logger.Info().
-		Str("method", method).
-		Str("url", reqURL).
-		Str("xRequestId", xRequestID).
-		Msg("athenahealth API request")
-
-	requestStart := time.Now()
+	h.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-	res, err := h.httpClient.Do(req)
+	res, err := h.doer().Do(req)
 	if err != nil {
-		return res, err
-	}
-	defer res.Body.Close()
-
-	var requestBodyLength int64
-	if srBody, ok := body.(*sizeRecordingReader); ok {
-		requestBodyLength = srBody.size
-	}
-
-	requestDuration := time.Since(requestStart)
+		if res == nil {
+			err = transportError(req, err)
+		}
 
-	err = h.stats.Request(method, path)
-	if err != nil {
 		return res, err
 	}
+	defer res.Body.Close()
 
 	responseError := res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices
 	if responseError {
-		err = h.stats.ResponseError()
+		resBody, err := io.ReadAll(res.Body)
 		if err != nil {
 			return res, err
 		}
-	} else {
-		err = h.stats.ResponseSuccess()
+		// close original res.Body before overwriting
+		res.Body.Close()
+
+		res.Body = io.NopCloser(bytes.NewBuffer(resBody))
+
+		err = h.apiError(res, resBody)
+
+		return res, err
+	}
+
+	// Without out, the caller may want to read the body itself.
+	if out == nil {
+		resBody, err := io.ReadAll(res.Body)
 		if err != nil {
 			return res, err
 		}
+		res.Body.Close()
+
+		res.Body = io.NopCloser(bytes.NewBuffer(resBody))
+
+		return res, nil
+	}
+
+	err = decodeJSON(res.Body, out)
+	if err != nil {
+		return res, fmt.Errorf("Error unmarshaling response body: %s", err)
 	}
 
-	resBody, err := io.ReadAll(res.Body)
+	// Drain the body so the connection can be reused and its size recorded.
+	_, err = io.Copy(io.Discard, res.Body)
 	if err != nil {
 		return res, err
 	}
-	// close original req.Body before before overwriting
 	res.Body.Close()
 
-	res.Body = io.NopCloser(bytes.NewBuffer(resBody))
+	res.Body = http.NoBody
+
+	return res, nil
+}
+
+// apiError returns the APIError for an error response with body resBody.
+func (h *HTTPClient) apiError(res *http.Response, resBody []byte) *APIError {
+	err := newAPIError(res)
+
+	//nolint
+	json.Unmarshal(resBody, err)
+
+	err.Err = statusError(res.StatusCode, err.AthenaError+" "+err.AthenaDetailedMessage)
 
 	h.logger.Info().
-		Str("method", method).
-		Str("url", reqURL).
-		Int("statusCode", res.StatusCode).
-		Int("responseBodyLength", len(resBody)).
-		Int64("requestBodyLength", requestBodyLength).
-		Int64("requestContentLength", req.ContentLength).
-		Str("xRequestId", xRequestID).
-		Str("duration", requestDuration.String()).
-		Msg("athenahealth API response")
+		Str("athenaError", err.AthenaError).
+		Str("athenaDetailedMessage", h.logRedactor().Message(err.AthenaDetailedMessage)).
+		Msg("athenahealth API error")
 
-	if responseError {
-		err := &APIError{}
-		if res.StatusCode == http.StatusNotFound {
-			err.Err = ErrNotFound
+	return err
+}
+
+// token returns a cached token, or fetches a new one if none is cached.
+func (h *HTTPClient) token(ctx context.Context) (string, error) {
+	token, err := h.tokenCacher.Get(ctx)
+	if err != nil {
+		if !errors.Is(err, tokencacher.ErrTokenNotExist) && !errors.Is(err, tokencacher.ErrTokenExpired) {
+			return "", err
 		}
 
-		//nolint
-		json.Unmarshal(resBody, err)
+		return h.fetchToken(ctx, false)
+	}
 
-		err.HTTPResponse = res
+	return token, nil
+}
 
-		h.logger.Info().
-			Str("athenaError", err.AthenaError).
-			Str("athenaDetailedMessage", err.AthenaDetailedMessage).
-			Msg("athenahealth API error")
+// fetchToken fetches a new token and caches it. Concurrent callers share a
+// single call to the token provider. If invalidate is true, the cached token is
+// invalidated before fetching a new one.
+func (h *HTTPClient) fetchToken(ctx context.Context, invalidate bool) (string, error) {
+	ch := h.tokenGroup.DoChan(tokenFlightKey, func() (interface{}, error) {
+		// The fetch is shared, so it must not be canceled along with the
+		// context of whichever caller happened to start it.
+		flightCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.requestTimeout)
+		defer cancel()
 
-		return res, err
-	}
+		if invalidator, ok := h.tokenCacher.(TokenInvalidator); ok && invalidate {
+			err := invalidator.Invalidate(flightCtx)
+			if err != nil {
+				return "", err
+			}
+		}
 
-	if out != nil {
-		err = json.Unmarshal(resBody, out)
-		if err != nil {
-			return res, fmt.Errorf("Error unmarshaling response body: %s", err)
+		start := time.Now()
+
+		token, err := h.provideToken(flightCtx)
+
+		statsErr := h.stats.RecordTokenRefresh(stats.TokenRefresh{
+			Rejected: invalidate,
+			Success:  err == nil,
+			Duration: time.Since(start),
+		})
+		if statsErr != nil {
+			h.logger.Warn().Err(statsErr).Msg("athenahealth stats error")
 		}
+
+		return token, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+
+		return res.Val.(string), nil
 	}
+}
 
-	return res, nil
+// provideToken fetches a new token from the token provider and caches it.
+func (h *HTTPClient) provideToken(ctx context.Context) (string, error) {
+	token, expiresAt, err := h.tokenProvider.Provide(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Remove 1 minute from the expiration time to create a buffer to see
+	// if it resolves intermittent 401s.
+	err = h.tokenCacher.Set(context.Background(), token, expiresAt.Add(-1*time.Minute))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// refreshToken replaces a token that was rejected by athena. If the cached token
+// no longer matches the rejected one, another request already refreshed it.
+func (h *HTTPClient) refreshToken(ctx context.Context, rejected string) error {
+	cached, err := h.tokenCacher.Get(ctx)
+	if err == nil && cached != rejected {
+		return nil
+	}
+
+	_, err = h.fetchToken(ctx, true)
+
+	return err
 }
 
 type sizeRecordingReader struct {
@@ -294,21 +476,49 @@ func (srr *sizeRecordingReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// sizeRecordingReadCloser records the size of a response body and calls
+// onClose with it once the body is closed.
+type sizeRecordingReadCloser struct {
+	*sizeRecordingReader
+
+	closer  io.Closer
+	once    sync.Once
+	onClose func(size int64)
+}
+
+// onBodyClose replaces the body of res so that fn is called with the number of
+// bytes read from it once it is closed.
+func onBodyClose(res *http.Response, fn func(size int64)) {
+	res.Body = &sizeRecordingReadCloser{
+		sizeRecordingReader: newSizeRecordingReader(res.Body),
+		closer:              res.Body,
+		onClose:             fn,
+	}
+}
+
+func (srrc *sizeRecordingReadCloser) Close() error {
+	err := srrc.closer.Close()
+
+	srrc.once.Do(func() {
+		srrc.onClose(srrc.size)
+	})
+
+	return err
+}
+
 func (h *HTTPClient) WithLogger(logger *zerolog.Logger) *HTTPClient {
 	h.logger = logger
 
 	return h
 }
 
+// WithPreview selects PreviewEnvironment or ProdEnvironment.
 func (h *HTTPClient) WithPreview(preview bool) *HTTPClient {
-	h.preview = preview
-	h.setBaseURL()
-
-	if _, ok := h.tokenProvider.(*tokenprovider.Default); ok {
-		h.tokenProvider = tokenprovider.NewDefault(h.httpClient, h.clientID, h.secret, preview)
+	if preview {
+		return h.WithEnvironment(PreviewEnvironment)
 	}
 
-	return h
+	return h.WithEnvironment(ProdEnvironment)
 }
 
 func (h *HTTPClient) WithTokenProvider(provider TokenProvider) *HTTPClient {
@@ -323,14 +533,34 @@ func (h *HTTPClient) WithTokenCacher(cacher TokenCacher) *HTTPClient {
 	return h
 }
 
+// WithRateLimiter configures the client's rate limiter. If rateLimiter also
+// implements RequestRateLimiter, requests are limited per practice and
+// endpoint.
 func (h *HTTPClient) WithRateLimiter(rateLimiter RateLimiter) *HTTPClient {
+	h.rateLimiter = newRequestRateLimiter(rateLimiter)
+
+	return h
+}
+
+// WithRequestRateLimiter configures the client to rate limit requests per
+// practice and endpoint.
+func (h *HTTPClient) WithRequestRateLimiter(rateLimiter RequestRateLimiter) *HTTPClient {
 	h.rateLimiter = rateLimiter
 
 	return h
 }
 
+// WithStats configures the client's stats. If stats also implements
+// StatsRecorder, detailed stats are recorded.
 func (h *HTTPClient) WithStats(stats Stats) *HTTPClient {
-	h.stats = stats
+	h.stats = newStatsRecorder(stats)
+
+	return h
+}
+
+// WithStatsRecorder configures the client to record detailed stats.
+func (h *HTTPClient) WithStatsRecorder(recorder StatsRecorder) *HTTPClient {
+	h.stats = recorder
 
 	return h
 }
@@ -341,6 +571,18 @@ func (h *HTTPClient) WithRequestTimeout(requestTimeout time.Duration) *HTTPClien
 	return h
 }
 
+// WithRetryPolicy configures how failed requests are retried. A nil policy
+// disables retries.
+func (h *HTTPClient) WithRetryPolicy(retryPolicy *RetryPolicy) *HTTPClient {
+	if retryPolicy == nil {
+		retryPolicy = noRetryPolicy
+	}
+
+	h.retryPolicy = retryPolicy
+
+	return h
+}
+
 func (h *HTTPClient) Get(ctx context.Context, path string, query url.Values, out interface{}) (*http.Response, error) {
 	if len(query) > 0 {
 		path = fmt.Sprintf("%s?%s", path, query.Encode())
@@ -365,7 +607,7 @@ func (h *HTTPClient) PostForm(ctx context.Context, path string, v url.Values, ou
 		headers.Set("Content-Length", strconv.Itoa(r.Len()))
 	}
 
-	return h.request(ctx, http.MethodPost, path, body, headers, out)
+	return h.request(h.contextWithAuditForm(ctx, v), http.MethodPost, path, body, headers, out)
 }
 
 func (h *HTTPClient) PostFormReader(ctx context.Context, path string, fue *formURLEncoder, out interface{}) (*http.Response, error) {
@@ -373,16 +615,10 @@ func (h *HTTPClient) PostFormReader(ctx context.Context, path string, fue *formU
 	var headers = http.Header{}
 
 	if fue != nil {
-		pr, pw := io.Pipe()
-
-		go func() {
-			err := fue.Encode(ctx, pw)
-			//nolint
-			pw.CloseWithError(err)
-		}()
-
-		body = pr
+		body = fue.newReader(ctx)
 		headers.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		ctx = h.contextWithAuditForm(ctx, fue.scalarValues())
 	}
 
 	return h.request(ctx, http.MethodPost, path, body, headers, out)
@@ -401,7 +637,7 @@ func (h *HTTPClient) PutForm(ctx context.Context, path string, v url.Values, out
 		headers.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
-	return h.request(ctx, http.MethodPut, path, body, headers, out)
+	return h.request(h.contextWithAuditForm(ctx, v), http.MethodPut, path, body, headers, out)
 }
 
 func (h *HTTPClient) Delete(ctx context.Context, path string, body io.Reader, out interface{}) (*http.Response, error) {
@@ -417,15 +653,23 @@ func (h *HTTPClient) DeleteForm(ctx context.Context, path string, v url.Values,
 		headers.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
-	return h.request(ctx, http.MethodDelete, path, body, headers, out)
+	return h.request(h.contextWithAuditForm(ctx, v), http.MethodDelete, path, body, headers, out)
 }
 
-// APIError represents an error response from the athenahealth API.
+// APIError represents an error response from the athenahealth API, or a
+// successful response whose body reports that the request failed. Err is the
+// sentinel the error matches, such as ErrValidation or ErrRejected.
 type APIError struct {
 	Err                   error  `json:"-"`
 	AthenaError           string `json:"error"`
 	AthenaDetailedMessage string `json:"detailedmessage"`
 
+	// XRequestID, Method and Path (templated, e.g. /patients/:id:) identify
+	// the request that failed.
+	XRequestID string `json:"-"`
+	Method     string `json:"-"`
+	Path       string `json:"-"`
+
 	HTTPResponse *http.Response
 }
 
@@ -440,7 +684,18 @@ func (a *APIError) Error() string {
 		status = a.HTTPResponse.Status
 	}
 
-	return fmt.Sprintf("athenahealth API error (%s): %s (%s)", status, a.AthenaError, details)
+	msg := fmt.Sprintf("athenahealth API error (%s): %s (%s)", status, a.AthenaError, details)
+
+	// The request got no response, so there is nothing from athena to report.
+	if a.HTTPResponse == nil && len(a.AthenaError) == 0 && a.Err != nil {
+		msg = fmt.Sprintf("athenahealth API error: %s", a.Err)
+	}
+
+	if len(a.Method) > 0 {
+		msg = fmt.Sprintf("%s [%s %s X-Request-Id: %s]", msg, a.Method, a.Path, a.XRequestID)
+	}
+
+	return msg
 }
 
 func (a *APIError) Unwrap() error {
@@ -456,6 +711,10 @@ type PaginationResult struct {
 	NextOffset     int
 	PreviousOffset int
 	TotalCount     int
+
+	// Next is the URL of the next page returned by athena. It is empty on the
+	// last page.
+	Next string
 }
 
 type PaginationResponse struct {
@@ -481,5 +740,6 @@ func makePaginationResult(nextURL, previousURL string, totalCount int) *Paginati
 		NextOffset:     nextOffset,
 		PreviousOffset: previousOffset,
 		TotalCount:     totalCount,
+		Next:           nextURL,
 	}
 }
@@ -12,11 +12,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis"
 	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,6 +86,18 @@ func (t *testRateLimiter) Allowed(ctx context.Context, preview bool) (time.Durat
 	return 0, nil
 }
 
+type testRequestRateLimiter struct {
+	AllowRequestFunc func(req ratelimiter.Request) (time.Duration, error)
+}
+
+func (t *testRequestRateLimiter) AllowRequest(ctx context.Context, req ratelimiter.Request) (time.Duration, error) {
+	if t.AllowRequestFunc != nil {
+		return t.AllowRequestFunc(req)
+	}
+
+	return 0, nil
+}
+
 type testStats struct {
 	RequestFunc         func(method, path string) error
 	ResponseSuccessFunc func() error
@@ -108,6 +128,39 @@ func (t *testStats) ResponseError() error {
 	return nil
 }
 
+type testStatsRecorder struct {
+	testStats
+
+	responses      []stats.Response
+	rateLimitWaits []stats.RateLimitWait
+	rateLimitRates []stats.RateLimitRate
+	tokenRefreshes []stats.TokenRefresh
+}
+
+func (t *testStatsRecorder) RecordResponse(res stats.Response) error {
+	t.responses = append(t.responses, res)
+
+	return nil
+}
+
+func (t *testStatsRecorder) RecordRateLimitWait(wait stats.RateLimitWait) error {
+	t.rateLimitWaits = append(t.rateLimitWaits, wait)
+
+	return nil
+}
+
+func (t *testStatsRecorder) RecordRateLimitRate(rate stats.RateLimitRate) error {
+	t.rateLimitRates = append(t.rateLimitRates, rate)
+
+	return nil
+}
+
+func (t *testStatsRecorder) RecordTokenRefresh(refresh stats.TokenRefresh) error {
+	t.tokenRefreshes = append(t.tokenRefreshes, refresh)
+
+	return nil
+}
+
 func TestNewHTTPClient(t *testing.T) {
 	assert := assert.New(t)
 
@@ -122,7 +175,7 @@ func TestNewHTTPClient(t *testing.T) {
 	assert.Equal(key, athenaClient.clientID)
 
 	// Preview mode should default to true.
-	assert.True(athenaClient.preview)
+	assert.True(athenaClient.environment.Preview)
 
 	assert.NotNil(athenaClient.tokenProvider)
 	assert.NotNil(athenaClient.tokenCacher)
@@ -157,7 +210,7 @@ func TestAPIError_setBaseURL(t *testing.T) {
 	assert.Equal(expectedBaseURL, athenaClient.baseURL)
 
 	// Production base URL
-	athenaClient.preview = false
+	athenaClient.environment = ProdEnvironment
 	athenaClient.setBaseURL()
 	expectedBaseURL = fmt.Sprintf("%s%s", ProdBaseURL, practiceID)
 	assert.Equal(expectedBaseURL, athenaClient.baseURL)
@@ -280,6 +333,415 @@ func TestHTTPClient_rate_limit(t *testing.T) {
 	assert.True(called)
 }
 
+type sequenceTokenProvider struct {
+	calls int
+}
+
+func (s *sequenceTokenProvider) Provide(ctx context.Context) (string, time.Time, error) {
+	s.calls++
+
+	return fmt.Sprintf("token-%d", s.calls), time.Now().Add(time.Hour), nil
+}
+
+func TestHTTPClient_request_unauthorized_refreshes_token(t *testing.T) {
+	assert := assert.New(t)
+
+	var authorizations []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		w.Write([]byte(fmt.Sprintf(`{"body":"%s"}`, b)))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	tokenProvider := &sequenceTokenProvider{}
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokencacher.NewDefault())
+
+	var out map[string]string
+	res, err := athenaClient.PostForm(context.Background(), "/", url.Values{"foo": {"bar"}}, &out)
+
+	assert.NotNil(res)
+	assert.NoError(err)
+	assert.Equal("foo=bar", out["body"])
+	assert.Equal([]string{"Bearer token-1", "Bearer token-2"}, authorizations)
+	assert.Equal(2, tokenProvider.calls)
+}
+
+func TestHTTPClient_request_unauthorized_replays_once(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	tokenProvider := &sequenceTokenProvider{}
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokencacher.NewDefault())
+
+	res, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+
+	assert.NotNil(res)
+	assert.IsType(&APIError{}, err)
+	assert.Equal(http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(2, calls)
+	assert.Equal(2, tokenProvider.calls)
+}
+
+func TestHTTPClient_refreshToken_already_refreshed(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, "", "", "")
+
+	tokenProvider := &sequenceTokenProvider{}
+	tokenCacher := tokencacher.NewDefault()
+	tokenCacher.Set(context.Background(), "fresh", time.Now().Add(time.Hour))
+
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokenCacher)
+
+	err := athenaClient.refreshToken(context.Background(), "stale")
+	assert.NoError(err)
+	assert.Zero(tokenProvider.calls)
+
+	token, _ := tokenCacher.Get(context.Background())
+	assert.Equal("fresh", token)
+}
+
+type slowTokenProvider struct {
+	calls atomic.Int32
+	delay time.Duration
+}
+
+func (s *slowTokenProvider) Provide(ctx context.Context) (string, time.Time, error) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+
+	return testToken, time.Now().Add(time.Hour), nil
+}
+
+func TestHTTPClient_request_concurrent_token_fetch(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testClient(nil)
+	defer ts.Close()
+
+	tokenProvider := &slowTokenProvider{delay: 50 * time.Millisecond}
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokencacher.NewDefault())
+
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+			assert.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(int32(1), tokenProvider.calls.Load())
+}
+
+func TestHTTPClient_fetchToken_caller_canceled(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, "", "", "")
+
+	tokenProvider := &slowTokenProvider{delay: 50 * time.Millisecond}
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokencacher.NewDefault())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := athenaClient.fetchToken(ctx, false)
+	assert.ErrorIs(err, context.Canceled)
+
+	// The shared fetch is not canceled with the caller that started it.
+	token, err := athenaClient.fetchToken(context.Background(), false)
+	assert.NoError(err)
+	assert.Equal(testToken, token)
+	assert.Equal(int32(1), tokenProvider.calls.Load())
+}
+
+// largeOpenAppointmentSlots returns a ListOpenAppointmentSlots response with
+// 10,000 slots, the most athena returns in one page.
+func largeOpenAppointmentSlots(tb testing.TB) []byte {
+	b, err := os.ReadFile("./resources/ListOpenAppointmentSlots.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	res := map[string]interface{}{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		tb.Fatal(err)
+	}
+
+	slots := res["appointments"].([]interface{})
+
+	large := make([]interface{}, 0, 10000)
+	for len(large) < cap(large) {
+		large = append(large, slots[len(large)%len(slots)])
+	}
+
+	res["appointments"] = large
+	res["totalcount"] = len(large)
+
+	b, err = json.Marshal(res)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return b
+}
+
+// BenchmarkHTTPClient_ListOpenAppointmentSlots_large measures allocations of
+// decoding a large response through the client.
+func BenchmarkHTTPClient_ListOpenAppointmentSlots_large(b *testing.B) {
+	slots := largeOpenAppointmentSlots(b)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write(slots)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(slots)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := athenaClient.ListOpenAppointmentSlots(context.Background(), 1, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_decode_large compares decoding a large response from the body
+// stream with buffering it first, which the client used to do.
+func Benchmark_decode_large(b *testing.B) {
+	slots := largeOpenAppointmentSlots(b)
+
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(slots)))
+
+		for i := 0; i < b.N; i++ {
+			body, err := io.ReadAll(io.NopCloser(bytes.NewReader(slots)))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			out := &listOpenAppointmentSlotsResponse{}
+			if err := json.Unmarshal(body, out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(slots)))
+
+		for i := 0; i < b.N; i++ {
+			out := &listOpenAppointmentSlotsResponse{}
+			if err := decodeJSON(io.NopCloser(bytes.NewReader(slots)), out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkHTTPClient_GetPatient_parallel measures throughput of concurrent
+// GetPatient calls. The rate limiter sleeps to simulate a round trip to Redis.
+func BenchmarkHTTPClient_GetPatient_parallel(b *testing.B) {
+	patient, _ := os.ReadFile("./resources/GetPatient.json")
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write(patient)
+	}
+
+	for _, goroutines := range []int{1, 100, 250} {
+		b.Run(fmt.Sprintf("goroutines=%d", goroutines), func(b *testing.B) {
+			athenaClient, ts := testClient(h)
+			defer ts.Close()
+
+			ts.Client().Transport.(*http.Transport).MaxIdleConnsPerHost = goroutines
+
+			athenaClient.WithRateLimiter(&testRateLimiter{
+				AllowedFunc: func(preview bool) (time.Duration, error) {
+					time.Sleep(time.Millisecond)
+					return 0, nil
+				},
+			})
+
+			b.SetParallelism(max(goroutines/runtime.GOMAXPROCS(0), 1))
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, err := athenaClient.GetPatient(context.Background(), "1", nil)
+					if err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestHTTPClient_request_streaming(t *testing.T) {
+	assert := assert.New(t)
+
+	slots := largeOpenAppointmentSlots(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write(slots)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	recorder := &testStatsRecorder{}
+	athenaClient.WithStatsRecorder(recorder)
+
+	res, err := athenaClient.ListOpenAppointmentSlots(context.Background(), 1, nil)
+	assert.NoError(err)
+	assert.Len(res.Appointments, 10000)
+
+	assert.Len(recorder.responses, 1)
+	assert.Equal(int64(len(slots)), recorder.responses[0].ResponseBytes)
+}
+
+func TestHTTPClient_request_no_out(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"msg":"Hello World!"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	res, err := athenaClient.Get(context.Background(), "/", nil, nil)
+	assert.NoError(err)
+
+	b, err := io.ReadAll(res.Body)
+	assert.NoError(err)
+	assert.Equal(`{"msg":"Hello World!"}`, string(b))
+}
+
+func TestHTTPClient_request_stats(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{"msg":"Hello World!"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	rateLimited := false
+	recorder := &testStatsRecorder{}
+
+	athenaClient.
+		WithStatsRecorder(recorder).
+		WithTokenProvider(&sequenceTokenProvider{}).
+		WithTokenCacher(tokencacher.NewDefault()).
+		WithRateLimiter(&testRateLimiter{
+			AllowedFunc: func(preview bool) (time.Duration, error) {
+				if rateLimited {
+					return 0, nil
+				}
+
+				rateLimited = true
+
+				return time.Millisecond, ratelimiter.ErrRateExceeded
+			},
+		})
+
+	_, err := athenaClient.PostForm(context.Background(), "/patients/1", url.Values{"foo": {"bar"}}, nil)
+	assert.NoError(err)
+
+	assert.Len(recorder.responses, 2)
+	assert.Equal(http.StatusUnauthorized, recorder.responses[0].StatusCode)
+
+	res := recorder.responses[1]
+	assert.Equal(http.MethodPost, res.Method)
+	assert.Equal("/patients/1", res.Path)
+	assert.Equal(testPracticeID, res.PracticeID)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal(int64(len("foo=bar")), res.RequestBytes)
+	assert.Equal(int64(len(`{"msg":"Hello World!"}`)), res.ResponseBytes)
+	assert.NotZero(res.Duration)
+
+	assert.Equal([]stats.RateLimitWait{{
+		Method:     http.MethodPost,
+		Path:       "/patients/1",
+		PracticeID: testPracticeID,
+		Wait:       time.Millisecond,
+	}}, recorder.rateLimitWaits)
+
+	assert.Len(recorder.tokenRefreshes, 2)
+	assert.False(recorder.tokenRefreshes[0].Rejected)
+	assert.True(recorder.tokenRefreshes[1].Rejected)
+	assert.True(recorder.tokenRefreshes[1].Success)
+}
+
+func TestHTTPClient_request_legacy_stats(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	var requests []string
+	responseErrors := 0
+	athenaClient.WithStats(&testStats{
+		RequestFunc: func(method, path string) error {
+			requests = append(requests, method+" "+path)
+			return nil
+		},
+		ResponseSuccessFunc: func() error {
+			assert.Fail("unexpected ResponseSuccess")
+			return nil
+		},
+		ResponseErrorFunc: func() error {
+			responseErrors++
+			return nil
+		},
+	})
+
+	_, err := athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.ErrorIs(err, ErrNotFound)
+
+	assert.Equal([]string{"GET /patients/1"}, requests)
+	assert.Equal(1, responseErrors)
+}
+
 func TestHTTPClient_WithPreview(t *testing.T) {
 	assert := assert.New(t)
 
@@ -287,7 +749,8 @@ func TestHTTPClient_WithPreview(t *testing.T) {
 
 	athenaClient.WithPreview(false)
 
-	assert.False(athenaClient.preview)
+	assert.Equal(ProdEnvironment, athenaClient.environment)
+	assert.Equal(ProdBaseURL, athenaClient.apiURL)
 }
 
 func TestHTTPClient_WithTokenProvider(t *testing.T) {
@@ -320,7 +783,91 @@ func TestHTTPClient_WithRateLimiter(t *testing.T) {
 	rateLimiter := &testRateLimiter{}
 	athenaClient.WithRateLimiter(rateLimiter)
 
-	assert.Equal(rateLimiter, athenaClient.rateLimiter)
+	assert.Equal(&legacyRateLimiter{RateLimiter: rateLimiter}, athenaClient.rateLimiter)
+
+	memory := ratelimiter.NewMemory(0, 0)
+	athenaClient.WithRateLimiter(memory)
+
+	assert.Equal(memory, athenaClient.rateLimiter)
+}
+
+func TestHTTPClient_WithRequestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	var reqs []ratelimiter.Request
+
+	athenaClient, ts := testPracticeClient(nil)
+	defer ts.Close()
+
+	athenaClient.WithRequestRateLimiter(&testRequestRateLimiter{
+		AllowRequestFunc: func(req ratelimiter.Request) (time.Duration, error) {
+			reqs = append(reqs, req)
+
+			return 0, nil
+		},
+	})
+
+	_, err := athenaClient.Get(context.Background(), "/patients/123/documents", url.Values{"limit": []string{"10"}}, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.ForPractice("999").Get(context.Background(), "/departments", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal([]ratelimiter.Request{
+		{Preview: true, PracticeID: testPracticeID, Method: http.MethodGet, Endpoint: "/patients/:id:/documents", Rate: 5},
+		{Preview: true, PracticeID: "999", Method: http.MethodGet, Endpoint: "/departments", Rate: 5},
+	}, reqs)
+}
+
+func TestHTTPClient_adaptiveRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	requests := 0
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Too many requests"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	rateLimiter := ratelimiter.NewAdaptive(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 10, 0)
+
+	recorder := &testStatsRecorder{}
+
+	athenaClient.WithRateLimiter(rateLimiter).
+		WithStatsRecorder(recorder).
+		WithRetryPolicy(nil)
+
+	_, err = athenaClient.Get(context.Background(), "/departments", nil, nil)
+	assert.ErrorIs(err, ErrRateLimited)
+
+	_, err = athenaClient.Get(context.Background(), "/departments", nil, nil)
+	assert.NoError(err)
+
+	// The rate backed off on the 429 and can't recover until an interval has
+	// passed.
+	assert.Equal([]stats.RateLimitRate{
+		{PracticeID: testPracticeID, Rate: 5},
+		{PracticeID: testPracticeID, Rate: 5},
+	}, recorder.rateLimitRates)
 }
 
 func TestHTTPClient_WithStats(t *testing.T) {
@@ -331,7 +878,23 @@ func TestHTTPClient_WithStats(t *testing.T) {
 	stats := &testStats{}
 	athenaClient.WithStats(stats)
 
-	assert.Equal(stats, athenaClient.stats)
+	assert.Equal(&legacyStats{Stats: stats}, athenaClient.stats)
+
+	recorder := &testStatsRecorder{}
+	athenaClient.WithStats(recorder)
+
+	assert.Equal(recorder, athenaClient.stats)
+}
+
+func TestHTTPClient_WithStatsRecorder(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, "", "", "")
+
+	recorder := &testStatsRecorder{}
+	athenaClient.WithStatsRecorder(recorder)
+
+	assert.Equal(recorder, athenaClient.stats)
 }
 
 func TestHTTPClient_WithRequestTimeout(t *testing.T) {
@@ -346,6 +909,22 @@ func TestHTTPClient_WithRequestTimeout(t *testing.T) {
 	assert.Equal(requestTimeout, athenaClient.requestTimeout)
 }
 
+func TestHTTPClient_WithRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, "", "", "")
+	assert.Equal(noRetryPolicy, athenaClient.retryPolicy)
+
+	retryPolicy := NewRetryPolicy(3)
+	athenaClient.WithRetryPolicy(retryPolicy)
+
+	assert.Equal(retryPolicy, athenaClient.retryPolicy)
+
+	athenaClient.WithRetryPolicy(nil)
+
+	assert.Equal(noRetryPolicy, athenaClient.retryPolicy)
+}
+
 func TestHTTPClient_Get(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,233 @@
+package athenahealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/idempotency"
+)
+
+const (
+	// defaultIdempotencyTTL is how long results are kept by default.
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyPollInterval is how often a call waits for an in-flight call
+	// with the same idempotency key to finish.
+	idempotencyPollInterval = 100 * time.Millisecond
+
+	// idempotencyInFlightMargin is added to a call's deadline to get the TTL of
+	// its in-flight record, so the record outlives the call.
+	idempotencyInFlightMargin = 30 * time.Second
+)
+
+// ErrIdempotencyOutcomeUnknown is returned for an idempotency key whose first
+// call failed without telling whether athena performed the write, e.g. because
+// it timed out. Check whether the write happened before retrying with a new key.
+var ErrIdempotencyOutcomeUnknown = errors.New("outcome of earlier call with idempotency key is unknown")
+
+// IdempotencyStore stores the outcomes of calls made with an idempotency key.
+type IdempotencyStore interface {
+	// Reserve records key as in flight for ttl, unless there is a record for
+	// key already. It returns the existing record, or nil if key was reserved.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (*idempotency.Record, error)
+	// Complete replaces the record for key.
+	Complete(ctx context.Context, key string, record *idempotency.Record, ttl time.Duration) error
+	// Release removes the record for key.
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyKeyContextKey struct{}
+
+// ContextWithIdempotencyKey returns a copy of ctx that makes BookAppointment,
+// CreatePatient, CreateFinancialClaim, AddDocument, AddDocumentReader and
+// CreateAppointmentNote idempotent when an IdempotencyStore is configured.
+// Calls with the same key (per practice and operation) within the store's TTL
+// return the first call's result instead of writing again, and calls made
+// while the first is in flight wait for it.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set by ContextWithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+
+	return key, ok && len(key) > 0
+}
+
+// WithIdempotencyStore configures the store used for calls made with
+// ContextWithIdempotencyKey. Results are kept for ttl, or 24 hours if ttl is
+// zero.
+func (h *HTTPClient) WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) *HTTPClient {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	h.idempotencyStore = store
+	h.idempotencyTTL = ttl
+
+	return h
+}
+
+// idempotent calls fn unless ctx carries an idempotency key that has been used
+// for operation before, in which case the earlier call's result is returned.
+//
+// fn keeps running if ctx is cancelled, so that a caller that gave up waiting
+// can retry with the same key and get the result once athena responds.
+//
+// Operations are not idempotent on athena's side, so their requests are not
+// retried by the default RetryPolicy even if their method is PUT.
+func idempotent[T any](ctx context.Context, h *HTTPClient, operation string, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+
+	ctx = contextWithNonIdempotent(ctx)
+
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok || h.idempotencyStore == nil || h.dryRun {
+		return fn(ctx)
+	}
+
+	practiceID := h.practiceID
+	if id, ok := PracticeIDFromContext(ctx); ok {
+		practiceID = id
+	}
+
+	storeKey := fmt.Sprintf("%s:%s:%s", practiceID, operation, key)
+
+	deadline := time.Now().Add(h.requestTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.After(deadline) {
+		deadline = ctxDeadline
+	}
+
+	for {
+		record, err := h.idempotencyStore.Reserve(ctx, storeKey, time.Until(deadline)+idempotencyInFlightMargin)
+		if err != nil {
+			return zero, err
+		}
+
+		if record == nil {
+			break
+		}
+
+		switch record.State {
+		case idempotency.StateSucceeded:
+			out := zero
+
+			err = json.Unmarshal(record.Result, &out)
+			if err != nil {
+				return zero, err
+			}
+
+			return out, nil
+
+		case idempotency.StateUnknown:
+			return zero, fmt.Errorf("%w: %s", ErrIdempotencyOutcomeUnknown, record.Err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+
+	type result struct {
+		out T
+		err error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		call := &idempotentCall{}
+
+		callCtx, cancel := context.WithDeadline(contextWithIdempotentCall(context.WithoutCancel(ctx), call), deadline)
+		defer cancel()
+
+		out, err := fn(callCtx)
+
+		h.completeIdempotent(context.WithoutCancel(ctx), storeKey, out, err, call.sent.Load())
+
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.out, r.err
+
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// idempotentCall tracks whether any request of an idempotent call was sent.
+type idempotentCall struct {
+	sent atomic.Bool
+}
+
+type idempotentCallContextKey struct{}
+
+func contextWithIdempotentCall(ctx context.Context, call *idempotentCall) context.Context {
+	return context.WithValue(ctx, idempotentCallContextKey{}, call)
+}
+
+// markIdempotentCallSent records that a request made with ctx was sent to
+// athena.
+func markIdempotentCallSent(ctx context.Context) {
+	if call, ok := ctx.Value(idempotentCallContextKey{}).(*idempotentCall); ok {
+		call.sent.Store(true)
+	}
+}
+
+// completeIdempotent stores the outcome of a call made with storeKey. sent
+// reports whether any of the call's requests were sent to athena.
+func (h *HTTPClient) completeIdempotent(ctx context.Context, storeKey string, out interface{}, callErr error, sent bool) {
+	var err error
+
+	switch {
+	case callErr == nil:
+		var result []byte
+
+		result, err = json.Marshal(out)
+		if err == nil {
+			err = h.idempotencyStore.Complete(ctx, storeKey, &idempotency.Record{
+				State:  idempotency.StateSucceeded,
+				Result: result,
+			}, h.idempotencyTTL)
+		}
+
+	case !sent || writeNotPerformed(callErr):
+		err = h.idempotencyStore.Release(ctx, storeKey)
+
+	default:
+		err = h.idempotencyStore.Complete(ctx, storeKey, &idempotency.Record{
+			State: idempotency.StateUnknown,
+			Err:   h.logRedactor().Error(callErr).Error(),
+		}, h.idempotencyTTL)
+	}
+
+	if err != nil {
+		h.logger.Warn().Err(err).Str("key", storeKey).Msg("athenahealth idempotency store error")
+	}
+}
+
+// writeNotPerformed reports whether err proves that athena did not perform a
+// write: it was never sent, or athena responded with an error other than a
+// 5xx, or reported that it rejected the request.
+func writeNotPerformed(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrBulkheadFull) {
+		return true
+	}
+
+	apiErr := &APIError{}
+	if errors.As(err, &apiErr) && apiErr.HTTPResponse != nil {
+		return apiErr.HTTPResponse.StatusCode < 500
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// Memory stores idempotency records in memory. It only protects against
+// duplicates sent by the same process.
+type Memory struct {
+	entries map[string]memoryEntry
+	now     func() time.Time
+
+	lock sync.Mutex
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		entries: map[string]memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (m *Memory) Reserve(ctx context.Context, key string, ttl time.Duration) (*Record, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+
+	if entry, ok := m.entries[key]; ok && now.Before(entry.expiresAt) {
+		record := entry.record
+
+		return &record, nil
+	}
+
+	m.removeExpired(now)
+
+	m.entries[key] = memoryEntry{
+		record:    Record{State: StateInFlight},
+		expiresAt: now.Add(ttl),
+	}
+
+	return nil, nil
+}
+
+func (m *Memory) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.entries[key] = memoryEntry{
+		record:    *record,
+		expiresAt: m.now().Add(ttl),
+	}
+
+	return nil
+}
+
+func (m *Memory) Release(ctx context.Context, key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.entries, key)
+
+	return nil
+}
+
+// removeExpired deletes expired entries. It is called when keys are reserved
+// so the map doesn't grow without bound.
+func (m *Memory) removeExpired(now time.Time) {
+	for key, entry := range m.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
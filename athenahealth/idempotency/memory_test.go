@@ -0,0 +1,52 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+
+	m := NewMemory()
+	m.now = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+
+	record, err := m.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Nil(record)
+
+	record, err = m.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Equal(&Record{State: StateInFlight}, record)
+
+	err = m.Complete(ctx, "key", &Record{State: StateSucceeded, Result: json.RawMessage(`"1"`)}, time.Hour)
+	assert.NoError(err)
+
+	now = now.Add(30 * time.Minute)
+
+	record, err = m.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Equal(&Record{State: StateSucceeded, Result: json.RawMessage(`"1"`)}, record)
+
+	now = now.Add(time.Hour)
+
+	record, err = m.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Nil(record)
+
+	assert.NoError(m.Release(ctx, "key"))
+
+	record, err = m.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Nil(record)
+}
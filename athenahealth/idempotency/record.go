@@ -0,0 +1,24 @@
+package idempotency
+
+import "encoding/json"
+
+// State is the state of the call an idempotency key was used for.
+type State string
+
+const (
+	// StateInFlight means the call is still running.
+	StateInFlight State = "in-flight"
+	// StateSucceeded means the call succeeded and Record.Result holds its result.
+	StateSucceeded State = "succeeded"
+	// StateUnknown means the call failed in a way that doesn't tell whether
+	// athena performed it, e.g. it timed out.
+	StateUnknown State = "unknown"
+)
+
+// Record is what is stored for an idempotency key.
+type Record struct {
+	State  State           `json:"state"`
+	Result json.RawMessage `json:"result,omitempty"`
+	// Err describes the failure of calls in StateUnknown.
+	Err string `json:"err,omitempty"`
+}
@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const RedisDefaultPrefix = "athena_idempotency:"
+
+// Redis stores idempotency records in Redis so that duplicates are detected
+// across processes.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	if client == nil {
+		panic("client is nil")
+	}
+
+	r := &Redis{
+		client: client,
+		prefix: prefix,
+	}
+
+	if len(r.prefix) == 0 {
+		r.prefix = RedisDefaultPrefix
+	}
+
+	return r
+}
+
+func (r *Redis) Reserve(ctx context.Context, key string, ttl time.Duration) (*Record, error) {
+	inFlight, err := json.Marshal(&Record{State: StateInFlight})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		reserved, err := r.client.SetNX(ctx, r.prefix+key, inFlight, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		if reserved {
+			return nil, nil
+		}
+
+		b, err := r.client.Get(ctx, r.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// The record expired or was released in between; try again.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := &Record{}
+
+		err = json.Unmarshal(b, record)
+		if err != nil {
+			return nil, err
+		}
+
+		return record, nil
+	}
+}
+
+func (r *Redis) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, r.prefix+key, b, ttl).Err()
+}
+
+func (r *Redis) Release(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
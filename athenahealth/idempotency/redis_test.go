@@ -0,0 +1,51 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	r := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), "")
+
+	ctx := context.Background()
+
+	record, err := r.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Nil(record)
+	assert.True(s.Exists(RedisDefaultPrefix + "key"))
+
+	record, err = r.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Equal(&Record{State: StateInFlight}, record)
+
+	err = r.Complete(ctx, "key", &Record{State: StateSucceeded, Result: json.RawMessage(`"1"`)}, time.Hour)
+	assert.NoError(err)
+	assert.Equal(time.Hour, s.TTL(RedisDefaultPrefix+"key"))
+
+	record, err = r.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Equal(&Record{State: StateSucceeded, Result: json.RawMessage(`"1"`)}, record)
+
+	assert.NoError(r.Release(ctx, "key"))
+
+	record, err = r.Reserve(ctx, "key", time.Minute)
+	assert.NoError(err)
+	assert.Nil(record)
+}
@@ -0,0 +1,215 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/idempotency"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokencacher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClient_idempotent(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := int32(0)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Write([]byte(`[{"patientid":"100"}]`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "create-patient-1")
+
+	for i := 0; i < 2; i++ {
+		patientID, err := athenaClient.CreatePatient(ctx, &CreatePatientOptions{})
+		assert.NoError(err)
+		assert.Equal("100", patientID)
+	}
+
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+
+	// Without a key, or with another key, the write is sent.
+	_, err := athenaClient.CreatePatient(context.Background(), &CreatePatientOptions{})
+	assert.NoError(err)
+
+	_, err = athenaClient.CreatePatient(ContextWithIdempotencyKey(context.Background(), "create-patient-2"), &CreatePatientOptions{})
+	assert.NoError(err)
+
+	assert.Equal(int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPClient_idempotent_in_flight(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := int32(0)
+	received := make(chan struct{})
+	release := make(chan struct{})
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		close(received)
+		<-release
+
+		w.Write([]byte(`{"success":true,"claimids":["1"]}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "claim-1")
+
+	var wg sync.WaitGroup
+	results := make([][]string, 2)
+
+	for i := range results {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			claimIDs, err := athenaClient.CreateFinancialClaim(ctx, &CreateClaimOptions{})
+			assert.NoError(err)
+
+			results[i] = claimIDs
+		}(i)
+
+		if i == 0 {
+			<-received
+		}
+	}
+
+	time.Sleep(2 * idempotencyPollInterval)
+	close(release)
+	wg.Wait()
+
+	assert.Equal([][]string{{"1"}, {"1"}}, results)
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPClient_idempotent_caller_timeout(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := int32(0)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		time.Sleep(100 * time.Millisecond)
+
+		w.Write([]byte(`{"documentid":"10"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "document-1")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err := athenaClient.AddDocument(timeoutCtx, "1", &AddDocumentOptions{})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// The retry waits for the first call, which kept running, instead of
+	// adding the document again.
+	documentID, err := athenaClient.AddDocument(ctx, "1", &AddDocumentOptions{})
+	assert.NoError(err)
+	assert.Equal("10", documentID)
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPClient_idempotent_failures(t *testing.T) {
+	assert := assert.New(t)
+
+	statusCodes := []int{http.StatusBadRequest, http.StatusInternalServerError}
+	requests := int32(0)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&requests, 1)
+
+		w.WriteHeader(statusCodes[i-1])
+		w.Write([]byte(`{"error":"failed"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	athenaClient.WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "note-1")
+
+	// athena rejected the write, so a retry sends it again.
+	err := athenaClient.CreateAppointmentNote(ctx, "1", &CreateAppointmentNoteOptions{})
+	assert.ErrorIs(err, ErrValidation)
+
+	err = athenaClient.CreateAppointmentNote(ctx, "1", &CreateAppointmentNoteOptions{})
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+
+	// athena may have performed the write, so a retry is refused.
+	err = athenaClient.CreateAppointmentNote(ctx, "1", &CreateAppointmentNoteOptions{})
+	assert.ErrorIs(err, ErrIdempotencyOutcomeUnknown)
+	assert.Equal(int32(2), atomic.LoadInt32(&requests))
+}
+
+type failingTokenProvider struct {
+	fail atomic.Bool
+}
+
+func (f *failingTokenProvider) Provide(ctx context.Context) (string, time.Time, error) {
+	if f.fail.Load() {
+		return "", time.Time{}, errors.New("token unavailable")
+	}
+
+	return testToken, time.Now().Add(time.Minute), nil
+}
+
+func TestHTTPClient_idempotent_not_sent(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := int32(0)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Write([]byte(`{"success":"true"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	tokenProvider := &failingTokenProvider{}
+	tokenProvider.fail.Store(true)
+
+	athenaClient.WithTokenProvider(tokenProvider).WithTokenCacher(tokencacher.NewDefault())
+	athenaClient.WithIdempotencyStore(idempotency.NewMemory(), time.Hour)
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "note-1")
+
+	err := athenaClient.CreateAppointmentNote(ctx, "1", &CreateAppointmentNoteOptions{})
+	assert.Error(err)
+	assert.NotErrorIs(err, ErrIdempotencyOutcomeUnknown)
+
+	// The request was never sent, so the key is released and a retry sends it.
+	tokenProvider.fail.Store(false)
+
+	err = athenaClient.CreateAppointmentNote(ctx, "1", &CreateAppointmentNoteOptions{})
+	assert.NoError(err)
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+}
@@ -56,6 +56,8 @@ type InsurancePackage struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient-insurance#Create-patient's-insurance-package
 func (h *HTTPClient) CreatePatientInsurancePackage(ctx context.Context, opts *CreatePatientInsurancePackageOptions) (*InsurancePackage, error) {
+	ctx = contextWithAuditOperation(ctx, "CreatePatientInsurancePackage")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -88,6 +90,8 @@ func (h *HTTPClient) CreatePatientInsurancePackage(ctx context.Context, opts *Cr
 // POST /v1/{practiceid}/patients/{patientid}/insurances/{insuranceid}/reactivate
 // https://docs.athenahealth.com/api/api-ref/patient-insurance#Reactivate-patient's-specific-insurance-package
 func (h *HTTPClient) ReactivatePatientInsurancePackage(ctx context.Context, patientID, insuranceID string, expirationDate *time.Time) error {
+	ctx = contextWithAuditOperation(ctx, "ReactivatePatientInsurancePackage")
+
 	out := &MessageResponse{}
 
 	form := url.Values{}
@@ -96,13 +100,13 @@ func (h *HTTPClient) ReactivatePatientInsurancePackage(ctx context.Context, pati
 		form.Add("expirationdate", expirationDate.Format("01/02/2006"))
 	}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s/reactivate", patientID, insuranceID), form, &out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s/reactivate", patientID, insuranceID), form, &out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -127,6 +131,8 @@ type UpdatePatientInsurancePackageOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient-insurance#Update-patient's-specific-insurance-package
 func (h *HTTPClient) UpdatePatientInsurancePackage(ctx context.Context, opts *UpdatePatientInsurancePackageOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientInsurancePackage")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -163,13 +169,13 @@ func (h *HTTPClient) UpdatePatientInsurancePackage(ctx context.Context, opts *Up
 		form.Add("newsequencenumber", strconv.Itoa(*opts.NewSequenceNumber))
 	}
 
-	_, err := h.PutForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s", opts.PatientID, opts.InsuranceID), form, out)
+	res, err := h.PutForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s", opts.PatientID, opts.InsuranceID), form, out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -179,6 +185,8 @@ func (h *HTTPClient) UpdatePatientInsurancePackage(ctx context.Context, opts *Up
 // DELETE /v1/{practiceid}/patients/{patientid}/insurances/{insuranceid}
 // https://docs.athenahealth.com/api/api-ref/patient-insurance#Delete-patient's-specific-insurance-package
 func (h *HTTPClient) DeletePatientInsurancePackage(ctx context.Context, patientID, insuranceID, cancellationNote string) error {
+	ctx = contextWithAuditOperation(ctx, "DeletePatientInsurancePackage")
+
 	out := &MessageResponse{}
 
 	form := url.Values{}
@@ -187,13 +195,13 @@ func (h *HTTPClient) DeletePatientInsurancePackage(ctx context.Context, patientI
 		form.Add("cancellationnote", cancellationNote)
 	}
 
-	_, err := h.DeleteForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s", patientID, insuranceID), form, out)
+	res, err := h.DeleteForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s", patientID, insuranceID), form, out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return fmt.Errorf("unexpected response with message: %s", out.Message)
+		return rejectedError(res, out.Message)
 	}
 
 	return nil
@@ -222,6 +230,8 @@ type ListPatientInsurancePackagesResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient-insurance#Get-patient's-insurance-packages
 func (h *HTTPClient) ListPatientInsurancePackages(ctx context.Context, opts *ListPatientInsurancePackagesOptions) (*ListPatientInsurancePackagesResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListPatientInsurancePackages")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -255,6 +265,20 @@ func (h *HTTPClient) ListPatientInsurancePackages(ctx context.Context, opts *Lis
 	}, nil
 }
 
+// ListPatientInsurancePackagesPager returns a Pager over the insurance packages returned by ListPatientInsurancePackages.
+func (h *HTTPClient) ListPatientInsurancePackagesPager(opts *ListPatientInsurancePackagesOptions) *Pager[*InsurancePackage] {
+	return newPager(h, "ListPatientInsurancePackagesPager", func(ctx context.Context) ([]*InsurancePackage, *PaginationResult, error) {
+		res, err := h.ListPatientInsurancePackages(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.InsurancePackages, res.Pagination, nil
+	}, func(out *listPatientInsurancePackagesResponse) ([]*InsurancePackage, *PaginationResult) {
+		return out.Insurances, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
 type UploadPatientInsuranceCardImageOptions struct {
 	DepartmentID string
 	Image        []byte
@@ -274,6 +298,8 @@ type UploadPatientInsuranceCardImageResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/insurance-card-image#Upload-patient's-insurance-card-image
 func (h *HTTPClient) UploadPatientInsuranceCardImage(ctx context.Context, patientID, insuranceID string, opts *UploadPatientInsuranceCardImageOptions) (*UploadPatientInsuranceCardImageResult, error) {
+	ctx = contextWithAuditOperation(ctx, "UploadPatientInsuranceCardImage")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -288,11 +314,15 @@ func (h *HTTPClient) UploadPatientInsuranceCardImage(ctx context.Context, patien
 
 	form.Add("image", base64.StdEncoding.EncodeToString(opts.Image))
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s/image", patientID, insuranceID), form, &out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/insurances/%s/image", patientID, insuranceID), form, &out)
 	if err != nil {
 		return nil, err
 	}
 
+	if !out.Success {
+		return nil, rejectedError(res, "")
+	}
+
 	return &UploadPatientInsuranceCardImageResult{
 		Success: out.Success,
 	}, nil
@@ -309,6 +339,8 @@ type UploadPatientInsuranceCardImageReaderOptions struct {
 // POST /v1/{practiceid}/patients/{patientid}/insurances/{insuranceid}/image
 // https://docs.athenahealth.com/api/api-ref/insurance-card-image#Upload-patient's-insurance-card-image
 func (h *HTTPClient) UploadPatientInsuranceCardImageReader(ctx context.Context, patientID, insuranceID string, opts *UploadPatientInsuranceCardImageReaderOptions) (*UploadPatientInsuranceCardImageResult, error) {
+	ctx = contextWithAuditOperation(ctx, "UploadPatientInsuranceCardImageReader")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -323,11 +355,15 @@ func (h *HTTPClient) UploadPatientInsuranceCardImageReader(ctx context.Context,
 
 	form.AddReader("image", opts.Image)
 
-	_, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/insurances/%s/image", patientID, insuranceID), form, &out)
+	res, err := h.PostFormReader(ctx, fmt.Sprintf("/patients/%s/insurances/%s/image", patientID, insuranceID), form, &out)
 	if err != nil {
 		return nil, err
 	}
 
+	if !out.Success {
+		return nil, rejectedError(res, "")
+	}
+
 	return &UploadPatientInsuranceCardImageResult{
 		Success: out.Success,
 	}, nil
@@ -349,6 +385,8 @@ type GetPatientInsuranceCardImageResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/insurance-card-image#Get-patient's-insurance-card-image
 func (h *HTTPClient) GetPatientInsuranceCardImage(ctx context.Context, patientID, insuranceID string) (*GetPatientInsuranceCardImageResult, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPatientInsuranceCardImage")
+
 	out := &getPatientInsuranceCardImageResponse{}
 
 	_, err := h.Get(ctx, fmt.Sprintf("/patients/%s/insurances/%s/image", patientID, insuranceID), nil, &out)
@@ -203,6 +203,25 @@ func TestHTTPClient_UploadPatientInsuranceCardImage(t *testing.T) {
 	assert.True(result.Success)
 }
 
+func TestHTTPClient_UploadPatientInsuranceCardImage_rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.UploadPatientInsuranceCardImage(context.Background(), "123", "456", &UploadPatientInsuranceCardImageOptions{})
+	assert.ErrorIs(err, ErrRejected)
+
+	_, err = athenaClient.UploadPatientInsuranceCardImageReader(context.Background(), "123", "456", &UploadPatientInsuranceCardImageReaderOptions{
+		Image: strings.NewReader("test attachment contents"),
+	})
+	assert.ErrorIs(err, ErrRejected)
+}
+
 func TestHTTPClient_GetPatientInsurancePackage(t *testing.T) {
 	assert := assert.New(t)
 
@@ -59,6 +59,8 @@ type ListLabResultsResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/lab-result#Get-patient's-lab-results
 func (h *HTTPClient) ListLabResults(ctx context.Context, patientID string, departmentID string, opts *ListLabResultsOptions) (*ListLabResultsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListLabResults")
+
 	var requiredParamErrors []error
 	if len(patientID) == 0 {
 		requiredParamErrors = append(requiredParamErrors, errors.New("patientID is required"))
@@ -117,6 +119,20 @@ func (h *HTTPClient) ListLabResults(ctx context.Context, patientID string, depar
 	}, nil
 }
 
+// ListLabResultsPager returns a Pager over the lab results returned by ListLabResults.
+func (h *HTTPClient) ListLabResultsPager(patientID string, departmentID string, opts *ListLabResultsOptions) *Pager[*LabResult] {
+	return newPager(h, "ListLabResultsPager", func(ctx context.Context) ([]*LabResult, *PaginationResult, error) {
+		res, err := h.ListLabResults(ctx, patientID, departmentID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.LabResults, res.Pagination, nil
+	}, func(out *listLabResultsResponse) ([]*LabResult, *PaginationResult) {
+		return out.LabResults, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
 type LabResultAttachmentType string
 
 const (
@@ -174,6 +190,8 @@ type addLabResultDocumentResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-lab-result#Add-lab-result-document-to-patient's-chart
 func (h *HTTPClient) AddLabResultDocumentReader(ctx context.Context, patientID string, departmentID string, opts *AddLabResultDocumentReaderOptions) (int, error) {
+	ctx = contextWithAuditOperation(ctx, "AddLabResultDocumentReader")
+
 	var requiredParamErrors []error
 	if len(patientID) == 0 {
 		requiredParamErrors = append(requiredParamErrors, errors.New("patientID is required"))
@@ -227,13 +245,13 @@ func (h *HTTPClient) AddLabResultDocumentReader(ctx context.Context, patientID s
 
 	out := &addLabResultDocumentResponse{}
 
-	_, err := h.PostFormReader(ctx, fmt.Sprintf("patients/%s/documents/labresult", patientID), form, out)
+	res, err := h.PostFormReader(ctx, fmt.Sprintf("patients/%s/documents/labresult", patientID), form, out)
 	if err != nil {
 		return 0, err
 	}
 
 	if !out.Success {
-		return 0, errors.New(out.ErrorMessage)
+		return 0, rejectedError(res, out.ErrorMessage)
 	}
 
 	return out.LabResultID, nil
@@ -260,6 +278,8 @@ type AddLabResultDocumentOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-lab-result#Add-lab-result-document-to-patient's-chart
 func (h *HTTPClient) AddLabResultDocument(ctx context.Context, patientID string, departmentID string, opts *AddLabResultDocumentOptions) (int, error) {
+	ctx = contextWithAuditOperation(ctx, "AddLabResultDocument")
+
 	var requiredParamErrors []error
 	if len(patientID) == 0 {
 		requiredParamErrors = append(requiredParamErrors, errors.New("patientID is required"))
@@ -312,13 +332,13 @@ func (h *HTTPClient) AddLabResultDocument(ctx context.Context, patientID string,
 
 	out := &addLabResultDocumentResponse{}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("patients/%s/documents/labresult", patientID), form, out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("patients/%s/documents/labresult", patientID), form, out)
 	if err != nil {
 		return 0, err
 	}
 
 	if !out.Success {
-		return 0, errors.New(out.ErrorMessage)
+		return 0, rejectedError(res, out.ErrorMessage)
 	}
 
 	return out.LabResultID, nil
@@ -393,6 +413,8 @@ type ListChangedLabResultsResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-lab-result#Get-list-of-changes-in-lab-results-based-on-subscription
 func (h *HTTPClient) ListChangedLabResults(ctx context.Context, opts *ListChangedLabResultsOptions) (*ListChangedLabResultsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListChangedLabResults")
+
 	q := url.Values{}
 
 	if opts != nil {
@@ -432,3 +454,21 @@ func (h *HTTPClient) ListChangedLabResults(ctx context.Context, opts *ListChange
 		Pagination:        makePaginationResult(out.Next, out.Previous, out.TotalCount),
 	}, nil
 }
+
+// ListChangedLabResultsPager returns a Pager over the changed lab results returned by ListChangedLabResults.
+func (h *HTTPClient) ListChangedLabResultsPager(opts *ListChangedLabResultsOptions) *Pager[*ChangedLabResult] {
+	return newPager(h, "ListChangedLabResultsPager", func(ctx context.Context) ([]*ChangedLabResult, *PaginationResult, error) {
+		res, err := h.ListChangedLabResults(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.ChangedLabResults, res.Pagination, nil
+	}, func(out *listChangedLabResultsResponse) ([]*ChangedLabResult, *PaginationResult) {
+		if out.PaginationResponse == nil {
+			return out.LabResults, &PaginationResult{}
+		}
+
+		return out.LabResults, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
@@ -88,6 +88,8 @@ type SearchMedicationsResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/medication#Get-patient's-medication-list
 func (h *HTTPClient) ListMedications(ctx context.Context, patientID string, opts *ListMedicationsOptions) (*ListMedicationsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListMedications")
+
 	out := &ListMedicationsResult{}
 
 	q := url.Values{}
@@ -115,6 +117,8 @@ func (h *HTTPClient) ListMedications(ctx context.Context, patientID string, opts
 //
 // https://docs.athenahealth.com/api/api-ref/medication#Search-for-available-medications
 func (h *HTTPClient) SearchMedications(ctx context.Context, searchVal string) ([]*SearchMedicationsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "SearchMedications")
+
 	out := []*SearchMedicationsResult{}
 
 	q := url.Values{}
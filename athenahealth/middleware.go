@@ -0,0 +1,308 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/google/uuid"
+)
+
+// Doer sends an HTTP request and returns its response.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to a Doer.
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to add behavior around every request sent to athena.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middleware to the client. Middleware sees every attempt
+// of every request after the client has rate limited, authenticated, logged
+// and counted it, just before it is sent. Middleware added first runs first.
+//
+// The response body passed back through middleware is streamed from athena.
+// Middleware that reads it must replace it with an equivalent body.
+func (h *HTTPClient) WithMiddleware(middleware ...Middleware) *HTTPClient {
+	h.middleware = slices.Concat(h.middleware, middleware)
+
+	return h
+}
+
+// doer returns the middleware chain used to send requests. The client's own
+// behavior is implemented as the outermost middleware, in this order: circuit
+// breaking, bulkheads, rate limiting, authentication, X-Request-Id, logging
+// and stats.
+func (h *HTTPClient) doer() Doer {
+	middleware := slices.Concat([]Middleware{
+		h.circuitBreakerMiddleware,
+		h.bulkheadMiddleware,
+		h.rateLimitMiddleware,
+		h.authMiddleware,
+		requestIDMiddleware,
+		h.loggingMiddleware,
+		h.statsMiddleware,
+	}, h.middleware)
+
+	var doer Doer = DoerFunc(h.send)
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+
+	return doer
+}
+
+// send sends req with the client's http.Client.
+func (h *HTTPClient) send(req *http.Request) (*http.Response, error) {
+	requestInfoFromRequest(req).sent = true
+	markIdempotentCallSent(req.Context())
+
+	return h.httpClient.Do(req)
+}
+
+// requestInfo describes the attempt that a request is being sent for.
+type requestInfo struct {
+	path       string
+	xRequestID string
+	attempt    int
+	token      string
+	body       *sizeRecordingReader
+
+	rateLimitWait time.Duration
+
+	// sent is set once the request has passed through the middleware and is
+	// sent to athena.
+	sent bool
+}
+
+type requestInfoContextKey struct{}
+
+func contextWithRequestInfo(ctx context.Context, info *requestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoContextKey{}, info)
+}
+
+// requestInfoFromRequest returns the requestInfo of req. Requests that were
+// not built by HTTPClient get a requestInfo describing a single attempt.
+func requestInfoFromRequest(req *http.Request) *requestInfo {
+	if info, ok := req.Context().Value(requestInfoContextKey{}).(*requestInfo); ok {
+		return info
+	}
+
+	return &requestInfo{
+		path:    req.URL.Path,
+		attempt: 1,
+	}
+}
+
+func (h *HTTPClient) rateLimitMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		info := requestInfoFromRequest(req)
+
+		limitReq := ratelimiter.Request{
+			Preview:    h.environment.Preview,
+			PracticeID: h.practiceID,
+			Method:     req.Method,
+			Endpoint:   stats.CleanPath(info.path),
+			Rate:       h.environment.RateLimit,
+		}
+
+		for {
+			retryAfter, err := h.rateLimiter.AllowRequest(ctx, limitReq)
+			if err == nil {
+				break
+			}
+
+			if !errors.Is(err, ratelimiter.ErrRateExceeded) {
+				return nil, err
+			}
+
+			h.logger.Info().
+				Str("method", req.Method).
+				Str("url", h.logRedactor().URL(req.URL.String())).
+				Err(err).
+				Msg("athenahealth API request rate limited")
+
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting for rate limit retry interval: %w", ctx.Err())
+
+			case <-time.After(retryAfter):
+				info.rateLimitWait += retryAfter
+			}
+
+			err = h.stats.RecordRateLimitWait(stats.RateLimitWait{
+				Method:     req.Method,
+				Path:       info.path,
+				PracticeID: h.practiceID,
+				Wait:       retryAfter,
+			})
+			if err != nil {
+				h.logger.Warn().Err(err).Msg("athenahealth stats error")
+			}
+		}
+
+		res, err := next.Do(req)
+		if res != nil {
+			h.observeRateLimit(ctx, limitReq, res.StatusCode)
+		}
+
+		return res, err
+	})
+}
+
+// observeRateLimit lets an AdaptiveRateLimiter adjust its rate to a response.
+func (h *HTTPClient) observeRateLimit(ctx context.Context, req ratelimiter.Request, statusCode int) {
+	limiter, ok := h.rateLimiter.(AdaptiveRateLimiter)
+	if !ok {
+		return
+	}
+
+	rate, err := limiter.Observe(ctx, req, statusCode)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("athenahealth rate limiter error")
+
+		return
+	}
+
+	recorder, ok := h.stats.(RateLimitStatsRecorder)
+	if !ok {
+		return
+	}
+
+	err = recorder.RecordRateLimitRate(stats.RateLimitRate{
+		PracticeID: req.PracticeID,
+		Rate:       rate,
+	})
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("athenahealth stats error")
+	}
+}
+
+func (h *HTTPClient) authMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := h.token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		requestInfoFromRequest(req).token = token
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+		return next.Do(req)
+	})
+}
+
+func requestIDMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		info := requestInfoFromRequest(req)
+		if len(info.xRequestID) == 0 {
+			info.xRequestID = uuid.NewString()
+		}
+
+		req.Header.Set(XRequestIDHeaderKey, info.xRequestID)
+
+		return next.Do(req)
+	})
+}
+
+func (h *HTTPClient) loggingMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		info := requestInfoFromRequest(req)
+
+		h.logger.Info().
+			Str("method", req.Method).
+			Str("url", h.logRedactor().URL(req.URL.String())).
+			Str("xRequestId", info.xRequestID).
+			Int("attempt", info.attempt).
+			Msg("athenahealth API request")
+
+		requestStart := time.Now()
+
+		res, err := next.Do(req)
+		if err != nil {
+			return res, err
+		}
+
+		// The response is logged once its body has been read.
+		onBodyClose(res, func(responseBodyLength int64) {
+			requestDuration := time.Since(requestStart)
+
+			var requestBodyLength int64
+			if info.body != nil {
+				requestBodyLength = info.body.size
+			}
+
+			h.logger.Info().
+				Str("method", req.Method).
+				Str("url", h.logRedactor().URL(req.URL.String())).
+				Int("statusCode", res.StatusCode).
+				Int64("responseBodyLength", responseBodyLength).
+				Int64("requestBodyLength", requestBodyLength).
+				Int64("requestContentLength", req.ContentLength).
+				Str("xRequestId", info.xRequestID).
+				Int("attempt", info.attempt).
+				Str("duration", requestDuration.String()).
+				Msg("athenahealth API response")
+		})
+
+		return res, nil
+	})
+}
+
+func (h *HTTPClient) statsMiddleware(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		info := requestInfoFromRequest(req)
+
+		requestStart := time.Now()
+
+		res, err := next.Do(req)
+
+		record := func(responseBytes int64) {
+			responseStats := stats.Response{
+				Method:        req.Method,
+				Path:          info.path,
+				PracticeID:    h.practiceID,
+				Duration:      time.Since(requestStart),
+				ResponseBytes: responseBytes,
+			}
+
+			if info.body != nil {
+				responseStats.RequestBytes = info.body.size
+			}
+
+			if res != nil {
+				responseStats.StatusCode = res.StatusCode
+			}
+
+			statsErr := h.stats.RecordResponse(responseStats)
+			if statsErr != nil {
+				h.logger.Warn().Err(statsErr).Msg("athenahealth stats error")
+			}
+		}
+
+		if err != nil || res == nil {
+			record(0)
+
+			return res, err
+		}
+
+		// The response is recorded once its body has been read.
+		onBodyClose(res, record)
+
+		return res, nil
+	})
+}
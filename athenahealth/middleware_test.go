@@ -0,0 +1,134 @@
+package athenahealth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClient_WithMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("bar", r.Header.Get("X-Foo"))
+
+		w.Write([]byte(`{"msg":"Hello World!"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	var calls []string
+
+	athenaClient.WithMiddleware(
+		func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "first")
+
+				assert.Equal(fmt.Sprintf("Bearer %s", testToken), req.Header.Get("Authorization"))
+				assert.NotEmpty(req.Header.Get(XRequestIDHeaderKey))
+
+				req.Header.Set("X-Foo", "bar")
+
+				return next.Do(req)
+			})
+		},
+		func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "second")
+
+				res, err := next.Do(req)
+
+				assert.NoError(err)
+				assert.Equal(http.StatusOK, res.StatusCode)
+
+				return res, err
+			})
+		},
+	)
+
+	var out map[string]string
+	res, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, &out)
+
+	assert.NotNil(res)
+	assert.NoError(err)
+	assert.Equal("Hello World!", out["msg"])
+	assert.Equal([]string{"first", "second"}, calls)
+}
+
+func TestHTTPClient_WithMiddleware_short_circuit(t *testing.T) {
+	assert := assert.New(t)
+
+	called := false
+	h := func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	responseErrors := 0
+	athenaClient.WithStats(&testStats{
+		ResponseErrorFunc: func() error {
+			responseErrors++
+			return nil
+		},
+	})
+
+	athenaClient.WithMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     http.StatusText(http.StatusServiceUnavailable),
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":"chaos"}`)),
+				Request:    req,
+			}, nil
+		})
+	})
+
+	_, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+
+	apiErr := &APIError{}
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal("chaos", apiErr.AthenaError)
+	assert.False(called)
+	assert.Equal(1, responseErrors)
+}
+
+func TestHTTPClient_WithMiddleware_views(t *testing.T) {
+	assert := assert.New(t)
+
+	noop := func(next Doer) Doer { return next }
+
+	athenaClient := NewHTTPClient(&http.Client{}, testPracticeID, "", "").WithMiddleware(noop)
+	view := athenaClient.ForPractice("999").WithMiddleware(noop)
+
+	assert.Len(athenaClient.middleware, 1)
+	assert.Len(view.middleware, 2)
+}
+
+func Test_requestIDMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	var xRequestID string
+	doer := requestIDMiddleware(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		xRequestID = req.Header.Get(XRequestIDHeaderKey)
+		return nil, nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	doer.Do(req)
+
+	assert.NotEmpty(xRequestID)
+
+	req, _ = http.NewRequestWithContext(contextWithRequestInfo(context.Background(), &requestInfo{xRequestID: "foo"}), http.MethodGet, "/", nil)
+	doer.Do(req)
+
+	assert.Equal("foo", xRequestID)
+}
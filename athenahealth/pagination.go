@@ -0,0 +1,209 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// ErrTooManyItems is returned by Pager.CollectAll for lists with more items
+// than allowed.
+var ErrTooManyItems = errors.New("too many items")
+
+// PageError is yielded by a Pager when a page can't be fetched.
+type PageError struct {
+	// Page is the index of the page, starting at 0.
+	Page int
+	// Next is the next URL returned by athena that the page was requested
+	// with. It is empty for the first page.
+	Next string
+
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("athenahealth: fetching page %d: %s", e.Page, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// Page is a page of a paginated list.
+type Page[T any] struct {
+	// Index is the index of the page, starting at 0.
+	Index      int
+	Items      []T
+	Pagination *PaginationResult
+}
+
+// Pager iterates over the items of a paginated list endpoint. The first page
+// is requested with the options the Pager was created with, and later pages
+// with the next URL returned by athena until it returns none.
+//
+// Pagers are created by the ListXPager methods of HTTPClient, e.g.
+// ListPatientsPager.
+type Pager[T any] struct {
+	first func(ctx context.Context) ([]T, *PaginationResult, error)
+	next  func(ctx context.Context, next string) ([]T, *PaginationResult, error)
+}
+
+// newPager returns a Pager that fetches the first page with first and later
+// pages by decoding athena's response into R and extracting its items and
+// pagination with decode. Its requests are audited as operation, the name of
+// the ListXPager method.
+func newPager[T any, R any](h *HTTPClient, operation string, first func(context.Context) ([]T, *PaginationResult, error), decode func(*R) ([]T, *PaginationResult)) *Pager[T] {
+	return &Pager[T]{
+		first: func(ctx context.Context) ([]T, *PaginationResult, error) {
+			return first(contextWithAuditOperation(ctx, operation))
+		},
+		next: func(ctx context.Context, next string) ([]T, *PaginationResult, error) {
+			ctx = contextWithAuditOperation(ctx, operation)
+
+			path, err := h.nextPagePath(next)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			out := new(R)
+
+			_, err = h.Get(ctx, path, nil, out)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			items, pagination := decode(out)
+
+			return items, pagination, nil
+		},
+	}
+}
+
+// Pages returns an iterator over the pages of the list. If a page can't be
+// fetched or ctx is done, it yields a *PageError and stops.
+func (p *Pager[T]) Pages(ctx context.Context) iter.Seq2[*Page[T], error] {
+	return func(yield func(*Page[T], error) bool) {
+		next := ""
+
+		for index := 0; ; index++ {
+			var items []T
+			var pagination *PaginationResult
+
+			err := ctx.Err()
+			if err == nil {
+				if index == 0 {
+					items, pagination, err = p.first(ctx)
+				} else {
+					items, pagination, err = p.next(ctx, next)
+				}
+			}
+
+			if err != nil {
+				yield(nil, &PageError{Page: index, Next: next, Err: err})
+
+				return
+			}
+
+			if !yield(&Page[T]{Index: index, Items: items, Pagination: pagination}, nil) {
+				return
+			}
+
+			// Stop on an empty page or a repeated next URL too, so a
+			// misbehaving endpoint can't loop forever.
+			if pagination == nil || len(pagination.Next) == 0 || pagination.Next == next || len(items) == 0 {
+				return
+			}
+
+			next = pagination.Next
+		}
+	}
+}
+
+// All returns an iterator over the items of every page of the list. Pages are
+// fetched as the items are consumed. If a page can't be fetched or ctx is done,
+// it yields a *PageError and stops.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range p.Pages(ctx) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CollectAll returns the items of every page of the list. It fails with
+// ErrTooManyItems, without fetching the remaining pages, as soon as the list
+// is known to have more than maxItems items. A maxItems of zero or less
+// disables the check.
+//
+// If a page can't be fetched, the items of the pages before it are returned
+// with a *PageError.
+func (p *Pager[T]) CollectAll(ctx context.Context, maxItems int) ([]T, error) {
+	var all []T
+
+	for page, err := range p.Pages(ctx) {
+		if err != nil {
+			return all, err
+		}
+
+		tooMany := len(all)+len(page.Items) > maxItems ||
+			(page.Pagination != nil && page.Pagination.TotalCount > maxItems)
+
+		if maxItems > 0 && tooMany {
+			return all, fmt.Errorf("%w: list has more than %d items", ErrTooManyItems, maxItems)
+		}
+
+		all = append(all, page.Items...)
+	}
+
+	return all, nil
+}
+
+// nextPagePath returns the path, relative to the practice, of the next URL
+// returned by athena. Next URLs include the API version and practice, e.g.
+// /v1/195900/patients?offset=10, whatever base URL the client is configured
+// with.
+func (h *HTTPClient) nextPagePath(next string) (string, error) {
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+
+	path := u.Path
+
+	rest, ok := "", false
+
+	apiURL, err := url.Parse(h.apiURL)
+	if err == nil && len(apiURL.Path) > 0 {
+		rest, ok = strings.CutPrefix(path, apiURL.Path)
+	}
+
+	if !ok {
+		rest, ok = strings.CutPrefix(path, "/v1/")
+	}
+
+	if ok {
+		// Drop the practice ID. Requests are routed to the practice as usual.
+		_, path, _ = strings.Cut(rest, "/")
+		path = "/" + path
+	}
+
+	if len(u.RawQuery) > 0 {
+		path = fmt.Sprintf("%s?%s", path, u.RawQuery)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,222 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPaginatedDepartments(pages int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offset := 0
+		fmt.Sscan(r.URL.Query().Get("offset"), &offset)
+
+		next := ""
+		if offset/2+1 < pages {
+			next = fmt.Sprintf(`"next":"/%s/departments?limit=2&offset=%d",`, testPracticeID, offset+2)
+		}
+
+		fmt.Fprintf(w, `{"departments":[{"departmentid":"%d"},{"departmentid":"%d"}],%s"totalcount":%d}`, offset+1, offset+2, next, pages*2)
+	}
+}
+
+func TestPager_All(t *testing.T) {
+	assert := assert.New(t)
+
+	var paths []string
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+
+		testPaginatedDepartments(3)(w, r)
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	pager := athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{
+		Pagination: &PaginationOptions{Limit: 2},
+	})
+
+	var departmentIDs []string
+
+	for department, err := range pager.All(context.Background()) {
+		assert.NoError(err)
+
+		departmentIDs = append(departmentIDs, department.DepartmentID)
+	}
+
+	assert.Equal([]string{"1", "2", "3", "4", "5", "6"}, departmentIDs)
+
+	// Later pages are requested with athena's next URL.
+	assert.Equal([]string{
+		"/" + testPracticeID + "/departments?limit=2",
+		"/" + testPracticeID + "/departments?limit=2&offset=2",
+		"/" + testPracticeID + "/departments?limit=2&offset=4",
+	}, paths)
+}
+
+func TestPager_All_break(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int32
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		testPaginatedDepartments(3)(w, r)
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	for department := range athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{}).All(context.Background()) {
+		if department.DepartmentID == "2" {
+			break
+		}
+	}
+
+	// Pages are only fetched as items are consumed.
+	assert.Equal(int32(1), requests.Load())
+}
+
+func TestPager_Pages_error(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "2" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+
+			return
+		}
+
+		testPaginatedDepartments(3)(w, r)
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	var pages []int
+	var errs []error
+
+	for page, err := range athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{}).Pages(context.Background()) {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		pages = append(pages, page.Index)
+		assert.Equal(6, page.Pagination.TotalCount)
+	}
+
+	assert.Equal([]int{0}, pages)
+
+	if assert.Len(errs, 1) {
+		assert.ErrorIs(errs[0], ErrNotFound)
+
+		pageErr := &PageError{}
+		if assert.ErrorAs(errs[0], &pageErr) {
+			assert.Equal(1, pageErr.Page)
+			assert.Equal("/"+testPracticeID+"/departments?limit=2&offset=2", pageErr.Next)
+		}
+	}
+}
+
+func TestPager_Pages_contextCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testPracticeClient(testPaginatedDepartments(3))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var pages int
+
+	for _, err := range athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{}).Pages(ctx) {
+		if err != nil {
+			assert.ErrorIs(err, context.Canceled)
+
+			break
+		}
+
+		pages++
+		cancel()
+	}
+
+	assert.Equal(1, pages)
+}
+
+func TestPager_Pages_repeatedNext(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int32
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.Write([]byte(`{"departments":[{"departmentid":"1"}],"next":"/123456/departments?offset=1"}`))
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	departments, err := athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{}).CollectAll(context.Background(), 0)
+	assert.NoError(err)
+	assert.Len(departments, 2)
+	assert.Equal(int32(2), requests.Load())
+}
+
+func TestPager_CollectAll(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testPracticeClient(testPaginatedDepartments(3))
+	defer ts.Close()
+
+	pager := athenaClient.ListDepartmentsPager(&ListDepartmentsOptions{})
+
+	departments, err := pager.CollectAll(context.Background(), 6)
+	assert.NoError(err)
+	assert.Len(departments, 6)
+
+	// The total count is checked before the remaining pages are fetched.
+	departments, err = pager.CollectAll(context.Background(), 5)
+	assert.ErrorIs(err, ErrTooManyItems)
+	assert.Empty(departments)
+}
+
+func TestHTTPClient_nextPagePath(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(http.DefaultClient, testPracticeID, testAPIKey, testAPISecret)
+
+	path, err := athenaClient.nextPagePath("/v1/" + testPracticeID + "/patients/1/documents/admin?limit=10&offset=10")
+	assert.NoError(err)
+	assert.Equal("/patients/1/documents/admin?limit=10&offset=10", path)
+
+	path, err = athenaClient.nextPagePath(PreviewBaseURL + testPracticeID + "/claims?offset=5")
+	assert.NoError(err)
+	assert.Equal("/claims?offset=5", path)
+
+	// athena's next URLs don't include the path of a custom base URL, e.g. a
+	// gateway's.
+	athenaClient.WithEnvironment(Environment{
+		BaseURL: "https://gateway.example.com/athena/v1",
+		AuthURL: tokenprovider.PreviewAuthURL,
+	})
+
+	path, err = athenaClient.nextPagePath("/v1/" + testPracticeID + "/patients?offset=10")
+	assert.NoError(err)
+	assert.Equal("/patients?offset=10", path)
+
+	path, err = athenaClient.nextPagePath("/athena/v1/" + testPracticeID + "/patients?offset=10")
+	assert.NoError(err)
+	assert.Equal("/patients?offset=10", path)
+}
@@ -249,6 +249,8 @@ type GetPatientOptions struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Get-specific-patient-record
 func (h *HTTPClient) GetPatient(ctx context.Context, id string, opts *GetPatientOptions) (*Patient, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPatient")
+
 	out, q := []*Patient{}, url.Values{}
 
 	if opts != nil {
@@ -299,6 +301,8 @@ func (h *HTTPClient) GetPatient(ctx context.Context, id string, opts *GetPatient
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Get-specific-patient-record
 func (h *HTTPClient) GetPatients(ctx context.Context, id string, opts *GetPatientOptions) ([]*Patient, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPatients")
+
 	out, q := []*Patient{}, url.Values{}
 
 	if opts != nil {
@@ -366,6 +370,8 @@ type listPatientsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Get-list-of-patients-for-a-practice
 func (h *HTTPClient) ListPatients(ctx context.Context, opts *ListPatientsOptions) (*ListPatientsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListPatients")
+
 	out := &listPatientsResponse{}
 
 	q := url.Values{}
@@ -409,6 +415,43 @@ func (h *HTTPClient) ListPatients(ctx context.Context, opts *ListPatientsOptions
 	}, nil
 }
 
+// ListPatientsPager returns a Pager over the patients returned by ListPatients.
+func (h *HTTPClient) ListPatientsPager(opts *ListPatientsOptions) *Pager[*Patient] {
+	return newPager(h, "ListPatientsPager", func(ctx context.Context) ([]*Patient, *PaginationResult, error) {
+		res, err := h.ListPatients(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Patients, res.Pagination, nil
+	}, func(out *listPatientsResponse) ([]*Patient, *PaginationResult) {
+		return out.Patients, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
+// ListPatientsBulk returns every patient returned by ListPatients, fetching
+// the pages after the first concurrently. See BulkListOptions.
+func (h *HTTPClient) ListPatientsBulk(ctx context.Context, opts *ListPatientsOptions, bulkOpts *BulkListOptions) ([]*Patient, error) {
+	ctx = contextWithAuditOperation(ctx, "ListPatientsBulk")
+
+	pageOpts := ListPatientsOptions{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+
+	return bulkList(ctx, pageOpts.Pagination, bulkOpts, func(ctx context.Context, pagination *PaginationOptions) ([]*Patient, *PaginationResult, error) {
+		pageOpts := pageOpts
+		pageOpts.Pagination = pagination
+
+		res, err := h.ListPatients(ctx, &pageOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Patients, res.Pagination, nil
+	})
+}
+
 type (
 	UpdatePatientOptions struct {
 		Address1            *string
@@ -468,6 +511,8 @@ var (
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Update-specific-patient-record
 func (h *HTTPClient) UpdatePatient(ctx context.Context, patientID string, opts *UpdatePatientOptions) (*UpdatePatientResult, error) {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatient")
+
 	out := []*updatePatientResponse{}
 
 	form := url.Values{}
@@ -638,6 +683,8 @@ type patientPhoto struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient-photo#Get-patient's-photo
 func (h *HTTPClient) GetPatientPhoto(ctx context.Context, patientID string, opts *GetPatientPhotoOptions) (string, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPatientPhoto")
+
 	out := &patientPhoto{}
 
 	q := url.Values{}
@@ -662,6 +709,8 @@ func (h *HTTPClient) GetPatientPhoto(ctx context.Context, patientID string, opts
 //
 // https://docs.athenahealth.com/api/api-ref/patient-photo#Update-patient's-photo
 func (h *HTTPClient) UpdatePatientPhoto(ctx context.Context, patientID string, data []byte) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientPhoto")
+
 	form := url.Values{}
 	form.Add("image", base64.StdEncoding.EncodeToString(data))
 
@@ -675,6 +724,8 @@ func (h *HTTPClient) UpdatePatientPhoto(ctx context.Context, patientID string, d
 // POST /v1/{practiceid}/patients/{patientid}/photo
 // https://developer.athenahealth.com/docs/read/forms_and_documents/Patient_Photo#section-1
 func (h *HTTPClient) UpdatePatientPhotoReader(ctx context.Context, patientID string, r io.Reader) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientPhotoReader")
+
 	form := NewFormURLEncoder()
 	form.AddReader("image", r)
 
@@ -703,6 +754,8 @@ type listChangedPatientsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Get-list-of-changes-in-patient-records
 func (h *HTTPClient) ListChangedPatients(ctx context.Context, opts *ListChangedPatientOptions) ([]*Patient, error) {
+	ctx = contextWithAuditOperation(ctx, "ListChangedPatients")
+
 	out := &listChangedPatientsResponse{}
 
 	q := url.Values{}
@@ -771,6 +824,8 @@ type updatePatientInformationVerificationDetailsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/privacy-information-verification#Update-patient's-privacy-information-verification-details
 func (h *HTTPClient) UpdatePatientInformationVerificationDetails(ctx context.Context, patientID string, opts *UpdatePatientInformationVerificationDetailsOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientInformationVerificationDetails")
+
 	out := []*updatePatientInformationVerificationDetailsResponse{}
 	var form url.Values
 
@@ -807,15 +862,19 @@ func (h *HTTPClient) UpdatePatientInformationVerificationDetails(ctx context.Con
 		}
 	}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/privacyinformationverified", patientID), form, &out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/privacyinformationverified", patientID), form, &out)
 	if err != nil {
 		return err
 	}
 
-	if len(out) != 1 || !out[0].Success {
+	if len(out) != 1 {
 		return errors.New("unexpected response")
 	}
 
+	if !out[0].Success {
+		return rejectedError(res, "")
+	}
+
 	return nil
 }
 
@@ -835,6 +894,8 @@ type updatePatientMedicationHistoryConsentResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/medication-history-consent
 func (h *HTTPClient) UpdatePatientMedicationHistoryConsent(ctx context.Context, patientID string, opts *UpdatePatientMedicationHistoryConsentOptions) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientMedicationHistoryConsent")
+
 	out := []*updatePatientMedicationHistoryConsentResponse{}
 	var form url.Values
 
@@ -847,15 +908,19 @@ func (h *HTTPClient) UpdatePatientMedicationHistoryConsent(ctx context.Context,
 		form.Add("signaturename", opts.SignatureName)
 	}
 
-	_, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/medicationhistoryconsentverified", patientID), form, &out)
+	res, err := h.PostForm(ctx, fmt.Sprintf("/patients/%s/medicationhistoryconsentverified", patientID), form, &out)
 	if err != nil {
 		return err
 	}
 
-	if len(out) != 1 || out[0].Success == "false" {
+	if len(out) != 1 {
 		return errors.New("unexpected response")
 	}
 
+	if out[0].Success == "false" {
+		return rejectedError(res, "")
+	}
+
 	return nil
 }
 
@@ -865,6 +930,8 @@ func (h *HTTPClient) UpdatePatientMedicationHistoryConsent(ctx context.Context,
 //
 // https://docs.athenahealth.com/api/api-ref/patient-custom-fields#Get-custom-field-information-from-patient's-records
 func (h *HTTPClient) GetPatientCustomFields(ctx context.Context, patientID, departmentID string) ([]*CustomFieldValue, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPatientCustomFields")
+
 	out := []*CustomFieldValue{}
 
 	query := url.Values{}
@@ -890,6 +957,8 @@ type updatePatientCustomFieldsResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient-custom-fields#Update-custom-field-information-from-patient's-records
 func (h *HTTPClient) UpdatePatientCustomFields(ctx context.Context, patientID, departmentID string, customFields []*CustomFieldValue) error {
+	ctx = contextWithAuditOperation(ctx, "UpdatePatientCustomFields")
+
 	out := &updatePatientCustomFieldsResponse{}
 
 	customFieldsJSON, err := json.Marshal(customFields)
@@ -901,13 +970,13 @@ func (h *HTTPClient) UpdatePatientCustomFields(ctx context.Context, patientID, d
 	form.Add("departmentid", departmentID)
 	form.Add("customfields", string(customFieldsJSON))
 
-	_, err = h.PutForm(ctx, fmt.Sprintf("/patients/%s/customfields", patientID), form, out)
+	res, err := h.PutForm(ctx, fmt.Sprintf("/patients/%s/customfields", patientID), form, out)
 	if err != nil {
 		return err
 	}
 
 	if !out.Success {
-		return errors.New("unexpected response")
+		return rejectedError(res, "")
 	}
 
 	return nil
@@ -938,6 +1007,8 @@ type listPatientsMatchingCustomFieldResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Get-list-of-patients---matching-custom-field-criteria
 func (h *HTTPClient) ListPatientsMatchingCustomField(ctx context.Context, opts *ListPatientsMatchingCustomFieldOptions) (*ListPatientsMatchingCustomFieldResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListPatientsMatchingCustomField")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -966,6 +1037,20 @@ func (h *HTTPClient) ListPatientsMatchingCustomField(ctx context.Context, opts *
 	}, nil
 }
 
+// ListPatientsMatchingCustomFieldPager returns a Pager over the patients matching a custom field returned by ListPatientsMatchingCustomField.
+func (h *HTTPClient) ListPatientsMatchingCustomFieldPager(opts *ListPatientsMatchingCustomFieldOptions) *Pager[*Patient] {
+	return newPager(h, "ListPatientsMatchingCustomFieldPager", func(ctx context.Context) ([]*Patient, *PaginationResult, error) {
+		res, err := h.ListPatientsMatchingCustomField(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Patients, res.Pagination, nil
+	}, func(out *listPatientsMatchingCustomFieldResponse) ([]*Patient, *PaginationResult) {
+		return out.Patients, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
+
 type CreatePatientOptions struct {
 	Address1              string
 	Address2              string
@@ -998,6 +1083,8 @@ type createPatientResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/patient#Create-new-patient-record
 func (h *HTTPClient) CreatePatient(ctx context.Context, opts *CreatePatientOptions) (string, error) {
+	ctx = contextWithAuditOperation(ctx, "CreatePatient")
+
 	if opts == nil {
 		panic("opts is nil")
 	}
@@ -1028,18 +1115,20 @@ func (h *HTTPClient) CreatePatient(ctx context.Context, opts *CreatePatientOptio
 		form.Add("bypasspatientmatching", "true")
 	}
 
-	_, err := h.PostForm(ctx, "/patients", form, &out)
-	if err != nil {
-		return "", err
-	}
+	return idempotent(ctx, h, "CreatePatient", func(ctx context.Context) (string, error) {
+		res, err := h.PostForm(ctx, "/patients", form, &out)
+		if err != nil {
+			return "", err
+		}
 
-	if len(out) != 1 {
-		return "", errors.New("unexpected response")
-	}
+		if len(out) != 1 {
+			return "", errors.New("unexpected response")
+		}
 
-	if len(out[0].ErrorMessage) > 0 {
-		return "", errors.New(out[0].ErrorMessage)
-	}
+		if len(out[0].ErrorMessage) > 0 {
+			return "", rejectedError(res, out[0].ErrorMessage)
+		}
 
-	return out[0].PatientID, nil
+		return out[0].PatientID, nil
+	})
 }
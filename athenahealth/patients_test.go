@@ -412,6 +412,28 @@ func TestHTTPClient_CreatePatient(t *testing.T) {
 	assert.Equal("100", actualPatientID)
 }
 
+func TestHTTPClient_CreatePatient_error(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := os.ReadFile("./resources/CreatePatientError.json")
+		w.Write(b)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	_, err := athenaClient.CreatePatient(context.Background(), &CreatePatientOptions{})
+	assert.ErrorIs(err, ErrConflict)
+	assert.ErrorContains(err, "A duplicate patient was found")
+
+	apiErr := &APIError{}
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal(http.MethodPost, apiErr.Method)
+	assert.Equal("/patients", apiErr.Path)
+	assert.NotEmpty(apiErr.XRequestID)
+}
+
 func TestHTTPClient_UpdatePatient(t *testing.T) {
 	assert := assert.New(t)
 
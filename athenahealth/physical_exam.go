@@ -30,6 +30,8 @@ type PhysicalExam struct {
 //
 // https://docs.athenahealth.com/api/api-ref/physical-exam#Get-list-of-physical-exam-findings-and-notes-for-given-encounter
 func (h *HTTPClient) GetPhysicalExam(ctx context.Context, encounterID string, opts *GetPhysicalExamOpts) (*PhysicalExam, error) {
+	ctx = contextWithAuditOperation(ctx, "GetPhysicalExam")
+
 	var out PhysicalExam
 
 	if encounterID == "" {
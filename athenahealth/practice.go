@@ -0,0 +1,124 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type practiceIDContextKey struct{}
+
+// ContextWithPracticeID returns a copy of ctx that routes HTTPClient requests to
+// practiceID instead of the practice the client was created for.
+func ContextWithPracticeID(ctx context.Context, practiceID string) context.Context {
+	return context.WithValue(ctx, practiceIDContextKey{}, practiceID)
+}
+
+// PracticeIDFromContext returns the practice ID set by ContextWithPracticeID.
+func PracticeIDFromContext(ctx context.Context) (string, bool) {
+	practiceID, ok := ctx.Value(practiceIDContextKey{}).(string)
+
+	return practiceID, ok && len(practiceID) > 0
+}
+
+// practiceRegistry holds the per-practice state shared by a client and all of
+// the practice views created from it. Each practice's state is created once,
+// even when the first requests to the practice are concurrent.
+type practiceRegistry struct {
+	rateLimiterFn func(practiceID string) RateLimiter
+	statsFn       func(practiceID string) Stats
+
+	rateLimiters map[string]RequestRateLimiter
+	stats        map[string]StatsRecorder
+	lock         sync.Mutex
+}
+
+func (p *practiceRegistry) rateLimiter(practiceID string) RequestRateLimiter {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rateLimiter, ok := p.rateLimiters[practiceID]
+	if !ok {
+		if p.rateLimiters == nil {
+			p.rateLimiters = map[string]RequestRateLimiter{}
+		}
+
+		rateLimiter = newRequestRateLimiter(p.rateLimiterFn(practiceID))
+		p.rateLimiters[practiceID] = rateLimiter
+	}
+
+	return rateLimiter
+}
+
+func (p *practiceRegistry) practiceStats(practiceID string) StatsRecorder {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	stats, ok := p.stats[practiceID]
+	if !ok {
+		if p.stats == nil {
+			p.stats = map[string]StatsRecorder{}
+		}
+
+		stats = newStatsRecorder(p.statsFn(practiceID))
+		p.stats[practiceID] = stats
+	}
+
+	return stats
+}
+
+// PracticeID returns the ID of the practice the client sends requests to.
+func (h *HTTPClient) PracticeID() string {
+	return h.practiceID
+}
+
+// ForPractice returns a view of the client that sends requests to practiceID.
+// The view shares the HTTP transport, token provider and token cacher with h.
+// Rate limiting and stats are shared too, unless they are configured per
+// practice with WithPracticeRateLimiter and WithPracticeStats.
+//
+// Configure h before calling ForPractice; later changes to h are not reflected
+// in views that already exist.
+func (h *HTTPClient) ForPractice(practiceID string) *HTTPClient {
+	if practiceID == h.practiceID {
+		return h
+	}
+
+	view := *h
+	view.practiceID = practiceID
+	view.baseURL = fmt.Sprintf("%s%s", view.apiURL, practiceID)
+
+	if h.practices.rateLimiterFn != nil {
+		view.rateLimiter = h.practices.rateLimiter(practiceID)
+	}
+
+	if h.practices.statsFn != nil {
+		view.stats = h.practices.practiceStats(practiceID)
+	}
+
+	return &view
+}
+
+// WithPracticeRateLimiter configures a separate RateLimiter for every practice.
+// fn is called once per practice, the first time a request is made to it.
+func (h *HTTPClient) WithPracticeRateLimiter(fn func(practiceID string) RateLimiter) *HTTPClient {
+	h.practices.rateLimiterFn = fn
+
+	if fn != nil {
+		h.rateLimiter = h.practices.rateLimiter(h.practiceID)
+	}
+
+	return h
+}
+
+// WithPracticeStats configures a separate Stats for every practice. fn is called
+// once per practice, the first time a request is made to it.
+func (h *HTTPClient) WithPracticeStats(fn func(practiceID string) Stats) *HTTPClient {
+	h.practices.statsFn = fn
+
+	if fn != nil {
+		h.stats = h.practices.practiceStats(h.practiceID)
+	}
+
+	return h
+}
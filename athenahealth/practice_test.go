@@ -0,0 +1,203 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPracticeClient(h http.HandlerFunc) (*HTTPClient, *httptest.Server) {
+	athenaClient, ts := testClient(h)
+
+	athenaClient.apiURL = ts.URL + "/"
+	athenaClient.baseURL = fmt.Sprintf("%s%s", athenaClient.apiURL, athenaClient.practiceID)
+
+	return athenaClient, ts
+}
+
+func TestHTTPClient_ForPractice(t *testing.T) {
+	assert := assert.New(t)
+
+	var paths []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	view := athenaClient.ForPractice("999")
+
+	assert.Equal("999", view.PracticeID())
+	assert.Equal(testPracticeID, athenaClient.PracticeID())
+	assert.Equal(athenaClient.tokenCacher, view.tokenCacher)
+	assert.Equal(athenaClient.tokenGroup, view.tokenGroup)
+	assert.Equal(athenaClient.httpClient, view.httpClient)
+	assert.Same(athenaClient, athenaClient.ForPractice(testPracticeID))
+
+	_, err := view.Get(context.Background(), "/patients/1", nil, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.Get(context.Background(), "/patients/1", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal([]string{"/999/patients/1", "/" + testPracticeID + "/patients/1"}, paths)
+}
+
+func TestHTTPClient_request_practice_from_context(t *testing.T) {
+	assert := assert.New(t)
+
+	var paths []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}
+
+	athenaClient, ts := testPracticeClient(h)
+	defer ts.Close()
+
+	ctx := ContextWithPracticeID(context.Background(), "999")
+
+	_, err := athenaClient.Get(ctx, "/patients/1", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal([]string{"/999/patients/1"}, paths)
+}
+
+func TestPracticeIDFromContext(t *testing.T) {
+	assert := assert.New(t)
+
+	_, ok := PracticeIDFromContext(context.Background())
+	assert.False(ok)
+
+	_, ok = PracticeIDFromContext(ContextWithPracticeID(context.Background(), ""))
+	assert.False(ok)
+
+	practiceID, ok := PracticeIDFromContext(ContextWithPracticeID(context.Background(), "999"))
+	assert.True(ok)
+	assert.Equal("999", practiceID)
+}
+
+func TestHTTPClient_WithPracticeRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testPracticeClient(nil)
+	defer ts.Close()
+
+	allowed := map[string]int{}
+	athenaClient.WithPracticeRateLimiter(func(practiceID string) RateLimiter {
+		return &testRateLimiter{
+			AllowedFunc: func(preview bool) (time.Duration, error) {
+				allowed[practiceID]++
+				return 0, nil
+			},
+		}
+	})
+
+	_, err := athenaClient.Get(context.Background(), "/", nil, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.ForPractice("999").Get(context.Background(), "/", nil, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.ForPractice("999").Get(context.Background(), "/", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal(map[string]int{testPracticeID: 1, "999": 2}, allowed)
+	assert.Same(athenaClient.ForPractice("999").rateLimiter, athenaClient.ForPractice("999").rateLimiter)
+}
+
+func TestHTTPClient_WithPracticeStats(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testPracticeClient(nil)
+	defer ts.Close()
+
+	requests := map[string]int{}
+	athenaClient.WithPracticeStats(func(practiceID string) Stats {
+		return &testStats{
+			RequestFunc: func(method, path string) error {
+				requests[practiceID]++
+				return nil
+			},
+		}
+	})
+
+	ctx := ContextWithPracticeID(context.Background(), "999")
+
+	_, err := athenaClient.Get(ctx, "/", nil, nil)
+	assert.NoError(err)
+
+	_, err = athenaClient.Get(context.Background(), "/", nil, nil)
+	assert.NoError(err)
+
+	assert.Equal(map[string]int{testPracticeID: 1, "999": 1}, requests)
+}
+
+func TestHTTPClient_ForPractice_concurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient, ts := testPracticeClient(nil)
+	defer ts.Close()
+
+	var lock sync.Mutex
+	rateLimiters := map[string]int{}
+	stats := map[string]int{}
+
+	athenaClient.
+		WithPracticeRateLimiter(func(practiceID string) RateLimiter {
+			// Give concurrent first calls time to race.
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			rateLimiters[practiceID]++
+
+			return &testRateLimiter{}
+		}).
+		WithPracticeStats(func(practiceID string) Stats {
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			stats[practiceID]++
+
+			return &testStats{}
+		})
+
+	var wg sync.WaitGroup
+
+	start := make(chan struct{})
+	views := make([]*HTTPClient, 20)
+
+	for i := range views {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+
+			views[i] = athenaClient.ForPractice(fmt.Sprintf("%d", i%2))
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	expected := map[string]int{testPracticeID: 1, "0": 1, "1": 1}
+	assert.Equal(expected, rateLimiters)
+	assert.Equal(expected, stats)
+
+	for i, view := range views {
+		assert.Same(views[i%2].rateLimiter, view.rateLimiter)
+		assert.Same(views[i%2].stats, view.stats)
+	}
+}
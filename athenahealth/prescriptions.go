@@ -58,6 +58,8 @@ type ListChangedPrescriptionsResult struct {
 //
 // https://docs.athenahealth.com/api/api-ref/document-type-prescription#Get-list-of-changes-in-prescriptions
 func (h *HTTPClient) ListChangedPrescriptions(ctx context.Context, opts *ListChangedPrescriptionsOptions) (*ListChangedPrescriptionsResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListChangedPrescriptions")
+
 	q := url.Values{}
 
 	if opts != nil {
@@ -92,3 +94,21 @@ func (h *HTTPClient) ListChangedPrescriptions(ctx context.Context, opts *ListCha
 		Pagination:           makePaginationResult(out.Next, out.Previous, out.TotalCount),
 	}, nil
 }
+
+// ListChangedPrescriptionsPager returns a Pager over the changed prescriptions returned by ListChangedPrescriptions.
+func (h *HTTPClient) ListChangedPrescriptionsPager(opts *ListChangedPrescriptionsOptions) *Pager[*ChangedPrescription] {
+	return newPager(h, "ListChangedPrescriptionsPager", func(ctx context.Context) ([]*ChangedPrescription, *PaginationResult, error) {
+		res, err := h.ListChangedPrescriptions(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.ChangedPrescriptions, res.Pagination, nil
+	}, func(out *listChangedPrescriptionsResponse) ([]*ChangedPrescription, *PaginationResult) {
+		if out.PaginationResponse == nil {
+			return out.ChangedPrescriptions, &PaginationResult{}
+		}
+
+		return out.ChangedPrescriptions, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
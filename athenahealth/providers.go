@@ -39,6 +39,8 @@ type Provider struct {
 //
 // https://docs.athenahealth.com/api/api-ref/provider#Get-information-of-given-provider
 func (h *HTTPClient) GetProvider(ctx context.Context, id string) (*Provider, error) {
+	ctx = contextWithAuditOperation(ctx, "GetProvider")
+
 	out := []*Provider{}
 
 	_, err := h.Get(ctx, fmt.Sprintf("/providers/%s", id), nil, &out)
@@ -69,6 +71,8 @@ type listChangedProvidersResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/provider#Get-list-of-changes-in-providers
 func (h *HTTPClient) ListChangedProviders(ctx context.Context, opts *ListChangedProviderOptions) ([]*Provider, error) {
+	ctx = contextWithAuditOperation(ctx, "ListChangedProviders")
+
 	out := &listChangedProvidersResponse{}
 
 	q := url.Values{}
@@ -119,6 +123,8 @@ type ListProvidersResponse struct {
 //
 // https://docs.athenahealth.com/api/api-ref/provider-reference#Get-list-of-all-providers
 func (h *HTTPClient) ListProviders(ctx context.Context, opts *ListProvidersOptions) (*ListProvidersResult, error) {
+	ctx = contextWithAuditOperation(ctx, "ListProviders")
+
 	out := &ListProvidersResponse{}
 
 	q := url.Values{}
@@ -149,3 +155,17 @@ func (h *HTTPClient) ListProviders(ctx context.Context, opts *ListProvidersOptio
 		Pagination: makePaginationResult(out.Next, out.Previous, out.TotalCount),
 	}, nil
 }
+
+// ListProvidersPager returns a Pager over the providers returned by ListProviders.
+func (h *HTTPClient) ListProvidersPager(opts *ListProvidersOptions) *Pager[*Provider] {
+	return newPager(h, "ListProvidersPager", func(ctx context.Context) ([]*Provider, *PaginationResult, error) {
+		res, err := h.ListProviders(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Providers, res.Pagination, nil
+	}, func(out *ListProvidersResponse) ([]*Provider, *PaginationResult) {
+		return out.Providers, makePaginationResult(out.Next, out.Previous, out.TotalCount)
+	})
+}
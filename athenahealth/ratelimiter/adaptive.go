@@ -0,0 +1,236 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const adaptiveKeyPrefix = "athena_adaptive_rate_limit:"
+
+const defaultAdaptiveMinRate = 1
+const defaultAdaptiveDecreaseFactor = 0.5
+const defaultAdaptiveIncreaseStep = 1
+const defaultAdaptiveInterval = time.Second
+
+// adaptiveScript adjusts the rate stored at KEYS[1]. It decreases the rate at
+// most once per interval, so a burst of 429s only backs off once, and only
+// increases it once an interval has passed without a change.
+var adaptiveScript = redis.NewScript(`
+local max_rate = tonumber(ARGV[1])
+local min_rate = tonumber(ARGV[2])
+local action = ARGV[3]
+local factor = tonumber(ARGV[4])
+local step = tonumber(ARGV[5])
+local now = tonumber(ARGV[6])
+local interval = tonumber(ARGV[7])
+
+local state = redis.call("HMGET", KEYS[1], "rate", "increased_at", "decreased_at")
+local rate = tonumber(state[1]) or max_rate
+local increased_at = tonumber(state[2]) or 0
+local decreased_at = tonumber(state[3]) or 0
+
+if action == "decrease" and now - decreased_at >= interval then
+	rate = math.max(min_rate, rate * factor)
+	redis.call("HMSET", KEYS[1], "rate", tostring(rate), "decreased_at", ARGV[6])
+elseif action == "increase" and rate < max_rate and now - math.max(increased_at, decreased_at) >= interval then
+	rate = math.min(max_rate, rate + step)
+	redis.call("HMSET", KEYS[1], "rate", tostring(rate), "increased_at", ARGV[6])
+end
+
+return tostring(rate)
+`)
+
+// adaptiveAllowScript takes a token from the bucket stored at KEYS[2] at the
+// current rate stored at KEYS[1], or ARGV[1] if none is stored yet, and
+// otherwise returns the time in seconds until a token is available. Buckets
+// are stored like allowAllScript's, but rates can be fractional: requests are
+// let through one interval (1 / rate) apart, with bursts of a second's worth of
+// requests, or of one request at rates below one per second.
+var adaptiveAllowScript = redis.NewScript(`
+-- this script has side-effects, so it requires replicate commands mode
+redis.replicate_commands()
+
+local rate = tonumber(redis.call("HGET", KEYS[1], "rate")) or tonumber(ARGV[1])
+
+-- Times are relative to Jan 1, 2017, like redis_rate's.
+local now = redis.call("TIME")
+now = (now[1] - 1483228800) + (now[2] / 1000000)
+
+local interval = 1 / rate
+local burst = math.max(1, interval)
+local tat = math.max(tonumber(redis.call("GET", KEYS[2])) or now, now) + interval
+
+-- Differences below TIME's resolution are rounding errors.
+local retry_after = tat - burst - now
+if retry_after > 0.000001 then
+	return tostring(retry_after)
+end
+
+redis.call("SET", KEYS[2], string.format("%.17g", tat), "EX", math.ceil(tat - now))
+
+return "0"
+`)
+
+// Adaptive is a rate limiter that adjusts each practice's rate to athena's
+// responses: it backs off multiplicatively when athena responds with 429 Too
+// Many Requests, and recovers additively while requests succeed (AIMD). Rates
+// start at, and never exceed, the configured rate. The rates are stored in
+// Redis and shared by every process using it.
+type Adaptive struct {
+	client *redis.Client
+
+	ratePreview int
+	rateProd    int
+
+	minRate        int
+	decreaseFactor float64
+	increaseStep   float64
+	interval       time.Duration
+
+	now func() time.Time
+}
+
+// NewAdaptive returns an Adaptive rate limiter allowing at most ratePreview and
+// rateProd requests per second. Rates of zero or less use the rate of the
+// client's Environment, like Redis.
+func NewAdaptive(client *redis.Client, ratePreview, rateProd int) *Adaptive {
+	if client == nil {
+		panic("client is nil")
+	}
+
+	return &Adaptive{
+		client: client,
+
+		ratePreview: max(ratePreview, 0),
+		rateProd:    max(rateProd, 0),
+
+		minRate:        defaultAdaptiveMinRate,
+		decreaseFactor: defaultAdaptiveDecreaseFactor,
+		increaseStep:   defaultAdaptiveIncreaseStep,
+		interval:       defaultAdaptiveInterval,
+
+		now: time.Now,
+	}
+}
+
+// WithMinRate configures the rate, in requests per second, that backing off
+// stops at. It defaults to 1.
+func (a *Adaptive) WithMinRate(minRate int) *Adaptive {
+	if minRate > 0 {
+		a.minRate = minRate
+	}
+
+	return a
+}
+
+// WithDecreaseFactor configures the factor, between 0 and 1, that the rate is
+// multiplied by on a 429 response. It defaults to 0.5.
+func (a *Adaptive) WithDecreaseFactor(factor float64) *Adaptive {
+	if factor > 0 && factor < 1 {
+		a.decreaseFactor = factor
+	}
+
+	return a
+}
+
+// WithIncrease configures the rate to recover by step requests per second
+// every interval while requests succeed. It defaults to 1 every second. The
+// rate is also decreased at most once per interval.
+func (a *Adaptive) WithIncrease(step float64, interval time.Duration) *Adaptive {
+	if step > 0 {
+		a.increaseStep = step
+	}
+
+	if interval > 0 {
+		a.interval = interval
+	}
+
+	return a
+}
+
+func (a *Adaptive) Allowed(ctx context.Context, preview bool) (time.Duration, error) {
+	return a.AllowRequest(ctx, Request{Preview: preview})
+}
+
+// AllowRequest limits requests per practice at the practice's current rate,
+// which may be fractional. It reads the rate and takes a token in a single
+// round trip to Redis.
+func (a *Adaptive) AllowRequest(ctx context.Context, req Request) (time.Duration, error) {
+	v, err := adaptiveAllowScript.Run(ctx, a.client, []string{a.stateKey(req), a.bucketKey(req)}, a.maxRate(req)).Text()
+	if err != nil {
+		return 0, err
+	}
+
+	retryAfter, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if retryAfter > 0 {
+		return time.Duration(retryAfter * float64(time.Second)), ErrRateExceeded
+	}
+
+	return 0, nil
+}
+
+// Rate returns the current rate, in requests per second, of req's practice.
+func (a *Adaptive) Rate(ctx context.Context, req Request) (float64, error) {
+	v, err := a.client.HGet(ctx, a.stateKey(req), "rate").Result()
+	if errors.Is(err, redis.Nil) {
+		return float64(a.maxRate(req)), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(v, 64)
+}
+
+// Observe adjusts the rate of req's practice to the status code of its
+// response and returns the rate afterwards.
+func (a *Adaptive) Observe(ctx context.Context, req Request, statusCode int) (float64, error) {
+	var action string
+
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		action = "decrease"
+
+	case statusCode >= 200 && statusCode < 400:
+		action = "increase"
+
+	default:
+		return a.Rate(ctx, req)
+	}
+
+	v, err := adaptiveScript.Run(ctx, a.client, []string{a.stateKey(req)},
+		a.maxRate(req),
+		a.minRate,
+		action,
+		a.decreaseFactor,
+		a.increaseStep,
+		a.now().UnixMilli(),
+		a.interval.Milliseconds(),
+	).Text()
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(v, 64)
+}
+
+func (a *Adaptive) stateKey(req Request) string {
+	return adaptiveKeyPrefix + req.practiceKey() + ":rate"
+}
+
+func (a *Adaptive) bucketKey(req Request) string {
+	return redisRateKeyPrefix + adaptiveKeyPrefix + req.practiceKey()
+}
+
+func (a *Adaptive) maxRate(req Request) int {
+	return req.rate(a.ratePreview, a.rateProd)
+}
@@ -0,0 +1,167 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptive_Observe(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+
+	rateLimiter := NewAdaptive(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 0, 8).WithMinRate(2).WithIncrease(1, time.Second)
+	rateLimiter.now = clock.Now
+
+	ctx := context.Background()
+	req := Request{PracticeID: "1"}
+
+	rate, err := rateLimiter.Rate(ctx, req)
+	assert.NoError(err)
+	assert.Equal(float64(8), rate)
+
+	// The rate doesn't exceed the configured rate.
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusOK)
+	assert.NoError(err)
+	assert.Equal(float64(8), rate)
+
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+	assert.Equal(float64(4), rate)
+
+	// A burst of 429s only backs off once per interval.
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+	assert.Equal(float64(4), rate)
+
+	clock.Advance(time.Second)
+
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+	assert.Equal(float64(2), rate)
+
+	clock.Advance(time.Second)
+
+	// The rate doesn't go below the minimum rate.
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+	assert.Equal(float64(2), rate)
+
+	// Successes only increase the rate once an interval has passed without a
+	// change.
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusOK)
+	assert.NoError(err)
+	assert.Equal(float64(2), rate)
+
+	clock.Advance(time.Second)
+
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusOK)
+	assert.NoError(err)
+	assert.Equal(float64(3), rate)
+
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusCreated)
+	assert.NoError(err)
+	assert.Equal(float64(3), rate)
+
+	// Other errors don't change the rate.
+	clock.Advance(time.Second)
+
+	rate, err = rateLimiter.Observe(ctx, req, http.StatusInternalServerError)
+	assert.NoError(err)
+	assert.Equal(float64(3), rate)
+
+	// Practices and processes sharing Redis share rates.
+	other := NewAdaptive(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 0, 8)
+
+	rate, err = other.Rate(ctx, req)
+	assert.NoError(err)
+	assert.Equal(float64(3), rate)
+
+	rate, err = other.Rate(ctx, Request{PracticeID: "2"})
+	assert.NoError(err)
+	assert.Equal(float64(8), rate)
+}
+
+func TestAdaptive_AllowRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	rateLimiter := NewAdaptive(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 4, 0)
+
+	ctx := context.Background()
+	req := Request{Preview: true, PracticeID: "1"}
+
+	_, err = rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+
+	for range 2 {
+		retryAfter, err := rateLimiter.AllowRequest(ctx, req)
+		assert.Zero(retryAfter)
+		assert.NoError(err)
+	}
+
+	retryAfter, err := rateLimiter.AllowRequest(ctx, req)
+	assert.NotZero(retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
+
+func TestAdaptive_AllowRequest_fractional(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	now := time.Unix(1700000000, 0)
+	s.SetTime(now)
+
+	rateLimiter := NewAdaptive(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 0, 3)
+
+	ctx := context.Background()
+	req := Request{PracticeID: "1"}
+
+	rate, err := rateLimiter.Observe(ctx, req, http.StatusTooManyRequests)
+	assert.NoError(err)
+	assert.Equal(1.5, rate)
+
+	_, err = rateLimiter.AllowRequest(ctx, req)
+	assert.NoError(err)
+
+	// Requests are let through 2/3 of a second apart, not a second apart as at
+	// a rate of 1.
+	retryAfter, err := rateLimiter.AllowRequest(ctx, req)
+	assert.InDelta(333*time.Millisecond, retryAfter, float64(time.Millisecond))
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	s.SetTime(now.Add(667 * time.Millisecond))
+
+	_, err = rateLimiter.AllowRequest(ctx, req)
+	assert.NoError(err)
+}
@@ -15,3 +15,7 @@ func NewDefault() *Default {
 func (d *Default) Allowed(ctx context.Context, preview bool) (time.Duration, error) {
 	return 0, nil
 }
+
+func (d *Default) AllowRequest(ctx context.Context, req Request) (time.Duration, error) {
+	return 0, nil
+}
@@ -0,0 +1,240 @@
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleBucketTimeout is how long a bucket goes unused before Memory evicts it,
+// if it is full again by then.
+const idleBucketTimeout = time.Minute
+
+// Memory is an in-process token bucket rate limiter. Unlike Redis, its limits
+// are not shared between processes. Buckets that have been idle for a minute
+// are evicted, so practices and endpoints that stop sending requests don't
+// hold on to memory.
+type Memory struct {
+	ratePreview  int
+	rateProd     int
+	burstPreview int
+	burstProd    int
+
+	practiceRate  int
+	endpointRates EndpointRates
+
+	buckets map[string]*bucket
+	swept   time.Time
+	lock    sync.Mutex
+
+	now func() time.Time
+}
+
+type bucket struct {
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+
+	lock sync.Mutex
+}
+
+// NewMemory returns a Memory rate limiter allowing ratePreview and rateProd
+// requests per second. Rates of zero or less use the rate of the client's
+// Environment, or the same defaults as Redis. The burst defaults to one
+// second's worth of requests.
+func NewMemory(ratePreview, rateProd int) *Memory {
+	return &Memory{
+		ratePreview: max(ratePreview, 0),
+		rateProd:    max(rateProd, 0),
+
+		buckets: map[string]*bucket{},
+
+		now: time.Now,
+	}
+}
+
+func newBucket(rate, burst int) *bucket {
+	return &bucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// WithBurst configures how many requests can be sent at once after a quiet
+// period. A burst of zero or less keeps the default.
+func (m *Memory) WithBurst(burstPreview, burstProd int) *Memory {
+	if burstPreview > 0 {
+		m.burstPreview = burstPreview
+	}
+
+	if burstProd > 0 {
+		m.burstProd = burstProd
+	}
+
+	return m
+}
+
+// WithPracticeRate limits each practice to rate requests per second, within
+// the environment's rate. It applies to requests limited with AllowRequest. By
+// default practices share the environment's rate, which is how athena
+// enforces it.
+func (m *Memory) WithPracticeRate(rate int) *Memory {
+	m.practiceRate = max(rate, 0)
+
+	return m
+}
+
+// WithEndpointRates configures tighter limits for some endpoints. They apply
+// to requests limited with AllowRequest.
+func (m *Memory) WithEndpointRates(rates EndpointRates) *Memory {
+	m.endpointRates = rates
+
+	return m
+}
+
+func (m *Memory) Allowed(ctx context.Context, preview bool) (time.Duration, error) {
+	req := Request{Preview: preview}
+
+	retryAfter := m.bucket(req.environmentKey(), m.environmentBucket(req)).take(m.now())
+	if retryAfter > 0 {
+		return retryAfter, ErrRateExceeded
+	}
+
+	return 0, nil
+}
+
+// AllowRequest limits requests per environment, like Allowed, and per practice
+// and per endpoint if configured with WithPracticeRate and WithEndpointRates.
+// A request is only counted against any of its limits if all of them allow
+// it.
+func (m *Memory) AllowRequest(ctx context.Context, req Request) (time.Duration, error) {
+	buckets := []*bucket{m.bucket(req.environmentKey(), m.environmentBucket(req))}
+
+	if m.practiceRate > 0 && len(req.PracticeID) > 0 {
+		buckets = append(buckets, m.bucket(req.practiceKey(), func() *bucket {
+			return newBucket(m.practiceRate, m.practiceRate)
+		}))
+	}
+
+	if rate, ok := m.endpointRates[req.Endpoint]; ok && rate > 0 {
+		buckets = append(buckets, m.bucket(req.endpointKey(), func() *bucket {
+			return newBucket(rate, rate)
+		}))
+	}
+
+	now := m.now()
+
+	var retryAfter time.Duration
+	var taken []*bucket
+
+	for _, b := range buckets {
+		wait := b.take(now)
+		if wait > 0 {
+			retryAfter = max(retryAfter, wait)
+
+			continue
+		}
+
+		taken = append(taken, b)
+	}
+
+	if retryAfter > 0 {
+		for _, b := range taken {
+			b.giveBack()
+		}
+
+		return retryAfter, ErrRateExceeded
+	}
+
+	return 0, nil
+}
+
+// environmentBucket returns a func creating a bucket with the rate and burst of
+// req's environment.
+func (m *Memory) environmentBucket(req Request) func() *bucket {
+	return func() *bucket {
+		rate := req.rate(m.ratePreview, m.rateProd)
+
+		burst := m.burstProd
+		if req.Preview {
+			burst = m.burstPreview
+		}
+
+		if burst <= 0 {
+			burst = rate
+		}
+
+		return newBucket(rate, burst)
+	}
+}
+
+// bucket returns the bucket stored at key, creating it with create if needed.
+// Idle buckets are evicted at most once per idleBucketTimeout.
+func (m *Memory) bucket(key string, create func() *bucket) *bucket {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+	if now.Sub(m.swept) >= idleBucketTimeout {
+		m.swept = now
+
+		for k, b := range m.buckets {
+			if b.idle(now) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = create()
+		m.buckets[key] = b
+	}
+
+	return b
+}
+
+// take takes a token from the bucket, or returns how long until one is
+// available.
+func (b *bucket) take(now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+
+	if now.After(b.last) {
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// idle reports whether the bucket has gone unused for idleBucketTimeout and is
+// full, so that replacing it with a new bucket changes nothing.
+func (b *bucket) idle(now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	elapsed := now.Sub(b.last)
+
+	return elapsed >= idleBucketTimeout && b.tokens+elapsed.Seconds()*b.rate >= b.burst
+}
+
+// giveBack returns a token taken from the bucket.
+func (b *bucket) giveBack() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+1)
+}
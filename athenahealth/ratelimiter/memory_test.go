@@ -0,0 +1,295 @@
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now  time.Time
+	lock sync.Mutex
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+func TestMemory_Allowed(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(2, 0)
+	rateLimiter.now = clock.Now
+
+	for range 2 {
+		retryAfter, err := rateLimiter.Allowed(context.Background(), true)
+		assert.Zero(retryAfter)
+		assert.NoError(err)
+	}
+
+	retryAfter, err := rateLimiter.Allowed(context.Background(), true)
+	assert.Equal(500*time.Millisecond, retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// Prod has its own bucket at the default rate.
+	for range defaultRatePerSecProd {
+		_, err = rateLimiter.Allowed(context.Background(), false)
+		assert.NoError(err)
+	}
+
+	_, err = rateLimiter.Allowed(context.Background(), false)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	clock.Advance(250 * time.Millisecond)
+
+	retryAfter, err = rateLimiter.Allowed(context.Background(), true)
+	assert.Equal(250*time.Millisecond, retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	clock.Advance(250 * time.Millisecond)
+
+	retryAfter, err = rateLimiter.Allowed(context.Background(), true)
+	assert.Zero(retryAfter)
+	assert.NoError(err)
+
+	// Tokens don't accumulate beyond the burst.
+	clock.Advance(time.Hour)
+
+	for range 2 {
+		_, err = rateLimiter.Allowed(context.Background(), true)
+		assert.NoError(err)
+	}
+
+	_, err = rateLimiter.Allowed(context.Background(), true)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
+
+func TestMemory_WithBurst(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(1, 1).WithBurst(5, 0)
+	rateLimiter.now = clock.Now
+
+	for range 5 {
+		_, err := rateLimiter.Allowed(context.Background(), true)
+		assert.NoError(err)
+	}
+
+	retryAfter, err := rateLimiter.Allowed(context.Background(), true)
+	assert.Equal(time.Second, retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	_, err = rateLimiter.Allowed(context.Background(), false)
+	assert.NoError(err)
+
+	_, err = rateLimiter.Allowed(context.Background(), false)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
+
+func TestMemory_Allowed_concurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(10, 0)
+	rateLimiter.now = clock.Now
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := rateLimiter.Allowed(context.Background(), true)
+			if err == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(int32(10), allowed.Load())
+}
+
+func TestMemory_AllowRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(3, 0).WithEndpointRates(EndpointRates{
+		"/patients/:id:/documents": 1,
+	})
+	rateLimiter.now = clock.Now
+
+	documents := Request{Preview: true, PracticeID: "1", Method: "POST", Endpoint: "/patients/:id:/documents"}
+	departments := Request{Preview: true, PracticeID: "1", Method: "GET", Endpoint: "/departments"}
+
+	_, err := rateLimiter.AllowRequest(context.Background(), documents)
+	assert.NoError(err)
+
+	// The endpoint's rate is tighter than the practice's.
+	retryAfter, err := rateLimiter.AllowRequest(context.Background(), documents)
+	assert.Equal(time.Second, retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// Other endpoints share the rest of the practice's rate.
+	for range 2 {
+		_, err = rateLimiter.AllowRequest(context.Background(), departments)
+		assert.NoError(err)
+	}
+
+	_, err = rateLimiter.AllowRequest(context.Background(), departments)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// Practices share the environment's rate, like they do at athena.
+	documents.PracticeID = "2"
+
+	_, err = rateLimiter.AllowRequest(context.Background(), documents)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// So does Allowed.
+	_, err = rateLimiter.Allowed(context.Background(), true)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
+
+func TestMemory_WithPracticeRate(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(3, 0).WithPracticeRate(1)
+	rateLimiter.now = clock.Now
+
+	req := Request{Preview: true, PracticeID: "1", Method: "GET", Endpoint: "/departments"}
+
+	_, err := rateLimiter.AllowRequest(context.Background(), req)
+	assert.NoError(err)
+
+	retryAfter, err := rateLimiter.AllowRequest(context.Background(), req)
+	assert.Equal(time.Second, retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// The request rejected by its practice's rate didn't use up the
+	// environment's, which is left for the other practices.
+	for _, practiceID := range []string{"2", "3"} {
+		req.PracticeID = practiceID
+
+		_, err = rateLimiter.AllowRequest(context.Background(), req)
+		assert.NoError(err)
+	}
+
+	req.PracticeID = "4"
+
+	_, err = rateLimiter.AllowRequest(context.Background(), req)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
+
+func TestMemory_evict(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(2, 0).WithBurst(1000, 0).WithEndpointRates(EndpointRates{
+		"/patients/:id:/documents": 1,
+	})
+	rateLimiter.now = clock.Now
+
+	for range 200 {
+		_, err := rateLimiter.AllowRequest(context.Background(), Request{Preview: true, PracticeID: "1", Endpoint: "/departments"})
+		assert.NoError(err)
+	}
+
+	_, err := rateLimiter.AllowRequest(context.Background(), Request{Preview: true, PracticeID: "1", Endpoint: "/patients/:id:/documents"})
+	assert.NoError(err)
+
+	assert.Len(rateLimiter.buckets, 2)
+
+	// The endpoint's bucket is full again after a minute and evicted. The
+	// environment's isn't full yet, so it is kept.
+	clock.Advance(time.Minute)
+
+	_, err = rateLimiter.Allowed(context.Background(), true)
+	assert.NoError(err)
+
+	assert.Len(rateLimiter.buckets, 1)
+	assert.InDelta(float64(1000-201+120-1), rateLimiter.buckets["preview"].tokens, 0.001)
+}
+
+func TestMemory_AllowRequest_practiceExceeded(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(1, 0).WithEndpointRates(EndpointRates{
+		"/patients/:id:/documents": 5,
+	})
+	rateLimiter.now = clock.Now
+
+	documents := Request{Preview: true, PracticeID: "1", Method: "POST", Endpoint: "/patients/:id:/documents"}
+
+	allowed := 0
+
+	for range 5 {
+		_, err := rateLimiter.AllowRequest(context.Background(), documents)
+		if err == nil {
+			allowed++
+		}
+	}
+
+	assert.Equal(1, allowed)
+
+	// Requests rejected by the practice's limit don't use up the endpoint's.
+	assert.Equal(float64(4), rateLimiter.buckets[documents.endpointKey()].tokens)
+}
+
+func TestMemory_AllowRequest_rate(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	rateLimiter := NewMemory(0, 3)
+	rateLimiter.now = clock.Now
+
+	// Without a rate of its own, the limiter uses the request's.
+	req := Request{Preview: true, PracticeID: "1", Rate: 2}
+
+	for range 2 {
+		_, err := rateLimiter.AllowRequest(context.Background(), req)
+		assert.NoError(err)
+	}
+
+	_, err := rateLimiter.AllowRequest(context.Background(), req)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// The configured rate takes precedence.
+	req.Preview = false
+
+	for range 3 {
+		_, err = rateLimiter.AllowRequest(context.Background(), req)
+		assert.NoError(err)
+	}
+
+	_, err = rateLimiter.AllowRequest(context.Background(), req)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
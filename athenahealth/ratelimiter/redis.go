@@ -2,14 +2,57 @@ package ratelimiter
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/go-redis/redis_rate/v9"
 )
 
-const redisKeyPreview = "athena_rate_limit:preview"
-const redisKeyProd = "athena_rate_limit:prod"
+const redisKeyPrefix = "athena_rate_limit:"
+const redisKeyPreview = redisKeyPrefix + "preview"
+const redisKeyProd = redisKeyPrefix + "prod"
+
+// redisRateKeyPrefix is the prefix redis_rate adds to keys.
+const redisRateKeyPrefix = "rate:"
+
+// allowAllScript takes a token from each of the buckets stored at KEYS if all
+// of them have one, and otherwise returns the time in seconds until they do.
+// ARGV holds the rate of each bucket, in requests per second, which is also
+// its burst. Buckets are stored the same way as by redis_rate (GCRA).
+var allowAllScript = redis.NewScript(`
+-- this script has side-effects, so it requires replicate commands mode
+redis.replicate_commands()
+
+-- Times are relative to Jan 1, 2017, like redis_rate's.
+local now = redis.call("TIME")
+now = (now[1] - 1483228800) + (now[2] / 1000000)
+
+local retry_after = 0
+local tats = {}
+
+for i, key in ipairs(KEYS) do
+	local interval = 1 / tonumber(ARGV[i])
+	local tat = math.max(tonumber(redis.call("GET", key)) or now, now)
+
+	tats[i] = tat + interval
+
+	-- The bucket is empty if taking a token would put it more than a burst
+	-- (one second) ahead. Differences below TIME's resolution are rounding
+	-- errors.
+	retry_after = math.max(retry_after, tats[i] - 1 - now)
+end
+
+if retry_after > 0.000001 then
+	return tostring(retry_after)
+end
+
+for i, key in ipairs(KEYS) do
+	redis.call("SET", key, string.format("%.17g", tats[i]), "EX", math.ceil(tats[i] - now))
+end
+
+return "0"
+`)
 
 const defaultRatePerSecPreview = 5
 const defaultRatePerSecProd = 100
@@ -20,45 +63,55 @@ type Redis struct {
 
 	ratePreivew int
 	rateProd    int
+
+	practiceRate  int
+	endpointRates EndpointRates
 }
 
+// NewRedis returns a Redis rate limiter allowing ratePreview and rateProd
+// requests per second. Rates of zero or less use the rate of the client's
+// Environment, or 5 in preview and 100 otherwise.
 func NewRedis(client *redis.Client, ratePreview, rateProd int) *Redis {
 	if client == nil {
 		panic("client is nil")
 	}
 
-	if ratePreview <= 0 {
-		ratePreview = defaultRatePerSecPreview
-	}
-
-	if rateProd <= 0 {
-		rateProd = defaultRatePerSecProd
-	}
-
 	r := &Redis{
 		client:  client,
 		limiter: redis_rate.NewLimiter(client),
 
-		ratePreivew: ratePreview,
-		rateProd:    rateProd,
+		ratePreivew: max(ratePreview, 0),
+		rateProd:    max(rateProd, 0),
 	}
 
 	return r
 }
 
-func (r *Redis) Allowed(ctx context.Context, preview bool) (time.Duration, error) {
-	var key string
-	var limit redis_rate.Limit
+// WithPracticeRate limits each practice to rate requests per second, within
+// the environment's rate. It applies to requests limited with AllowRequest. By
+// default practices share the environment's rate, which is how athena
+// enforces it.
+func (r *Redis) WithPracticeRate(rate int) *Redis {
+	r.practiceRate = max(rate, 0)
 
+	return r
+}
+
+// WithEndpointRates configures tighter limits for some endpoints. They apply
+// to requests limited with AllowRequest.
+func (r *Redis) WithEndpointRates(rates EndpointRates) *Redis {
+	r.endpointRates = rates
+
+	return r
+}
+
+func (r *Redis) Allowed(ctx context.Context, preview bool) (time.Duration, error) {
+	key := redisKeyProd
 	if preview {
 		key = redisKeyPreview
-		limit = redis_rate.PerSecond(r.ratePreivew)
-	} else {
-		key = redisKeyProd
-		limit = redis_rate.PerSecond(r.rateProd)
 	}
 
-	res, err := r.limiter.Allow(ctx, key, limit)
+	res, err := r.limiter.Allow(ctx, key, redis_rate.PerSecond(Request{Preview: preview}.rate(r.ratePreivew, r.rateProd)))
 	if err != nil {
 		return 0, err
 	}
@@ -69,3 +122,46 @@ func (r *Redis) Allowed(ctx context.Context, preview bool) (time.Duration, error
 
 	return 0, nil
 }
+
+// AllowRequest limits requests per environment, like Allowed, and per
+// practice and per endpoint if configured with WithPracticeRate and
+// WithEndpointRates. Limits are shared by every process using the same Redis.
+// A request is only counted against any of its limits if all of them allow
+// it.
+//
+// The limits are stored at these keys, which expire once their bucket is full
+// again:
+//
+//	rate:athena_rate_limit:{preview,prod}                          environment, shared with Allowed
+//	rate:athena_rate_limit:{preview,prod}:{practiceid}             practice
+//	rate:athena_rate_limit:{preview,prod}:{practiceid}:{endpoint}  endpoint
+func (r *Redis) AllowRequest(ctx context.Context, req Request) (time.Duration, error) {
+	keys := []string{redisRateKeyPrefix + redisKeyPrefix + req.environmentKey()}
+	rates := []interface{}{req.rate(r.ratePreivew, r.rateProd)}
+
+	if r.practiceRate > 0 && len(req.PracticeID) > 0 {
+		keys = append(keys, redisRateKeyPrefix+redisKeyPrefix+req.practiceKey())
+		rates = append(rates, r.practiceRate)
+	}
+
+	if endpointRate, ok := r.endpointRates[req.Endpoint]; ok && endpointRate > 0 {
+		keys = append(keys, redisRateKeyPrefix+redisKeyPrefix+req.endpointKey())
+		rates = append(rates, endpointRate)
+	}
+
+	v, err := allowAllScript.Run(ctx, r.client, keys, rates...).Text()
+	if err != nil {
+		return 0, err
+	}
+
+	retryAfter, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if retryAfter > 0 {
+		return time.Duration(retryAfter * float64(time.Second)), ErrRateExceeded
+	}
+
+	return 0, nil
+}
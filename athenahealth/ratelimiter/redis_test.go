@@ -54,3 +54,132 @@ func TestRedis_Allowed(t *testing.T) {
 	assert.Zero(retryAfter)
 	assert.NoError(err)
 }
+
+func TestRedis_AllowRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	rateLimiter := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 3, 3).WithEndpointRates(EndpointRates{
+		"/patients/:id:/documents": 1,
+	})
+
+	documents := Request{PracticeID: "1", Method: "POST", Endpoint: "/patients/:id:/documents"}
+	departments := Request{PracticeID: "1", Method: "GET", Endpoint: "/departments"}
+
+	_, err = rateLimiter.AllowRequest(context.Background(), documents)
+	assert.NoError(err)
+
+	retryAfter, err := rateLimiter.AllowRequest(context.Background(), documents)
+	assert.NotZero(retryAfter)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	for range 2 {
+		_, err = rateLimiter.AllowRequest(context.Background(), departments)
+		assert.NoError(err)
+	}
+
+	_, err = rateLimiter.AllowRequest(context.Background(), departments)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// Practices share the environment's rate, like they do at athena.
+	documents.PracticeID = "2"
+
+	_, err = rateLimiter.AllowRequest(context.Background(), documents)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// So does Allowed.
+	_, err = rateLimiter.Allowed(context.Background(), false)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	assert.True(s.Exists("rate:athena_rate_limit:prod"))
+	assert.True(s.Exists("rate:athena_rate_limit:prod:1:/patients/:id:/documents"))
+	assert.False(s.Exists("rate:athena_rate_limit:prod:1"))
+}
+
+func TestRedis_WithPracticeRate(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	s.SetTime(time.Unix(1700000000, 0))
+
+	rateLimiter := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 0, 3).WithPracticeRate(1)
+
+	req := Request{PracticeID: "1", Method: "GET", Endpoint: "/departments"}
+
+	_, err = rateLimiter.AllowRequest(context.Background(), req)
+	assert.NoError(err)
+
+	_, err = rateLimiter.AllowRequest(context.Background(), req)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// The request rejected by its practice's rate didn't use up the
+	// environment's, which is left for the other practices.
+	for _, practiceID := range []string{"2", "3"} {
+		req.PracticeID = practiceID
+
+		_, err = rateLimiter.AllowRequest(context.Background(), req)
+		assert.NoError(err)
+	}
+
+	req.PracticeID = "4"
+
+	_, err = rateLimiter.AllowRequest(context.Background(), req)
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	assert.True(s.Exists("rate:athena_rate_limit:prod:1"))
+}
+
+func TestRedis_AllowRequest_practiceExceeded(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	now := time.Unix(1700000000, 0)
+	s.SetTime(now)
+
+	rateLimiter := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), 0, 2).WithEndpointRates(EndpointRates{
+		"/patients/:id:/documents": 1,
+	})
+
+	documents := Request{PracticeID: "1", Method: "POST", Endpoint: "/patients/:id:/documents"}
+	departments := Request{PracticeID: "1", Method: "GET", Endpoint: "/departments"}
+
+	for range 2 {
+		_, err = rateLimiter.AllowRequest(context.Background(), departments)
+		assert.NoError(err)
+	}
+
+	retryAfter, err := rateLimiter.AllowRequest(context.Background(), documents)
+	assert.InDelta(500*time.Millisecond, retryAfter, float64(time.Millisecond))
+	assert.ErrorIs(err, ErrRateExceeded)
+
+	// The practice's limit rejected the request, so the endpoint's token is
+	// still available once the practice's limit allows another request.
+	s.SetTime(now.Add(500 * time.Millisecond))
+
+	_, err = rateLimiter.AllowRequest(context.Background(), documents)
+	assert.NoError(err)
+
+	_, err = rateLimiter.AllowRequest(context.Background(), documents)
+	assert.ErrorIs(err, ErrRateExceeded)
+}
@@ -0,0 +1,70 @@
+package ratelimiter
+
+import "fmt"
+
+// Request describes a request to be rate limited.
+type Request struct {
+	Preview    bool
+	PracticeID string
+	Method     string
+	// Endpoint is the request path with IDs replaced by ":id:", e.g.
+	// /patients/:id:/documents.
+	Endpoint string
+	// Rate is the environment's rate limit in requests per second. Limiters
+	// constructed with a rate of zero use it, or their default if it is zero
+	// too.
+	Rate int
+}
+
+// EndpointRates maps endpoints, e.g. "/patients/:id:/documents", to the
+// requests per second allowed to them per practice. Requests to these
+// endpoints are limited by both their endpoint's rate and the environment's.
+type EndpointRates map[string]int
+
+// environmentKey returns the key of the bucket shared by every request to r's
+// environment. athena enforces its rate limit per API key, which is per
+// environment.
+func (r Request) environmentKey() string {
+	if r.Preview {
+		return "preview"
+	}
+
+	return "prod"
+}
+
+// practiceKey returns the key of the bucket shared by every request to r's
+// practice. Requests without a practice share the environment's bucket.
+func (r Request) practiceKey() string {
+	if len(r.PracticeID) == 0 {
+		return r.environmentKey()
+	}
+
+	return fmt.Sprintf("%s:%s", r.environmentKey(), r.PracticeID)
+}
+
+// rate returns the rate of r's environment: ratePreview or rateProd if
+// configured, otherwise r's Rate, otherwise the environment's default.
+func (r Request) rate(ratePreview, rateProd int) int {
+	rate := rateProd
+	if r.Preview {
+		rate = ratePreview
+	}
+
+	if rate > 0 {
+		return rate
+	}
+
+	if r.Rate > 0 {
+		return r.Rate
+	}
+
+	if r.Preview {
+		return defaultRatePerSecPreview
+	}
+
+	return defaultRatePerSecProd
+}
+
+func (r Request) endpointKey() string {
+	return fmt.Sprintf("%s:%s", r.practiceKey(), r.Endpoint)
+}
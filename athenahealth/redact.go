@@ -0,0 +1,225 @@
+package athenahealth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces masked values.
+const redactedValue = "REDACTED"
+
+// DefaultLogSafeQueryKeys are the query keys whose values are logged as is by
+// the default Redactor. None of them identify a patient.
+var DefaultLogSafeQueryKeys = []string{
+	"appointmentcancelreasonid",
+	"appointmentdate",
+	"appointmentid",
+	"appointmentstatus",
+	"appointmenttime",
+	"appointmenttypeid",
+	"bypassscheduletimechecks",
+	"ccdaoutputformat",
+	"departmentid",
+	"documentsubclass",
+	"duration",
+	"encounterid",
+	"enddate",
+	"freeze",
+	"generic",
+	"hideduplicate",
+	"hospitalonly",
+	"ignorerestrictions",
+	"ignoreschedulablepermission",
+	"labresultstatus",
+	"leaveunprocessed",
+	"limit",
+	"limitlocalpatientid",
+	"medicationtype",
+	"newappointmentid",
+	"nopatientcase",
+	"offset",
+	"providerid",
+	"providerlist",
+	"reasonid",
+	"recipientcategory",
+	"requirescancellation",
+	"returnglobalid",
+	"serviceenddate",
+	"servicestartdate",
+	"shortname",
+	"showabnormaldetails",
+	"showalldepartments",
+	"showallpatientdepartmentstatus",
+	"showallproviderids",
+	"showcancelled",
+	"showcustomfields",
+	"showdeleted",
+	"showdiagnosisinfo",
+	"showfrozenslots",
+	"showhidden",
+	"showinsurance",
+	"showlocalpatientid",
+	"shownotperformedquestions",
+	"showpatientdetail",
+	"showportalonly",
+	"showportalstatus",
+	"showpreviouspatientids",
+	"showprocessedenddatetime",
+	"showprocessedstartdatetime",
+	"showstructured",
+	"showunansweredquestions",
+	"skipamendments",
+	"startdate",
+	"status",
+	"templateids",
+	"templatetypeonly",
+}
+
+// defaultRedactor is used by production clients that have not been configured
+// with WithLogRedactor.
+var defaultRedactor = NewRedactor(DefaultLogSafeQueryKeys...)
+
+// Redactor removes PHI from URLs and athena error messages before they are
+// logged. Query values are logged as is only if their key is allowed; all
+// other values are masked, or hashed if a hash key is configured.
+//
+// A nil *Redactor logs everything as is.
+type Redactor struct {
+	allowedKeys map[string]bool
+	hashKey     []byte
+}
+
+// NewRedactor returns a Redactor that logs the values of allowedKeys and masks
+// everything else.
+func NewRedactor(allowedKeys ...string) *Redactor {
+	r := &Redactor{
+		allowedKeys: make(map[string]bool, len(allowedKeys)),
+	}
+
+	for _, key := range allowedKeys {
+		r.allowedKeys[strings.ToLower(key)] = true
+	}
+
+	return r
+}
+
+// WithHashKey replaces masking with an HMAC-SHA256 of the value keyed by key,
+// so that the same value can be correlated across log lines without being
+// revealed.
+func (r *Redactor) WithHashKey(key []byte) *Redactor {
+	r.hashKey = key
+
+	return r
+}
+
+// URL returns rawURL with the values of query keys that are not allowed
+// redacted.
+func (r *Redactor) URL(rawURL string) string {
+	if r == nil {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return r.redact(rawURL)
+	}
+
+	if len(u.RawQuery) == 0 {
+		return rawURL
+	}
+
+	query := u.Query()
+
+	for key, values := range query {
+		if r.allowedKeys[strings.ToLower(key)] {
+			continue
+		}
+
+		for i, value := range values {
+			values[i] = r.redact(value)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// Message redacts free text from athena, such as AthenaDetailedMessage, which
+// may echo patient demographics.
+func (r *Redactor) Message(message string) string {
+	if r == nil || len(message) == 0 {
+		return message
+	}
+
+	return r.redact(message)
+}
+
+// Error returns err with URLs and athena detailed messages redacted.
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+
+	apiErr := &APIError{}
+	if errors.As(err, &apiErr) {
+		redacted := *apiErr
+		redacted.AthenaDetailedMessage = r.Message(apiErr.AthenaDetailedMessage)
+
+		if unavailable, ok := apiErr.Err.(*unavailableError); ok {
+			redacted.Err = &unavailableError{err: r.Error(unavailable.err)}
+		}
+
+		return &redacted
+	}
+
+	urlErr := &url.Error{}
+	if errors.As(err, &urlErr) {
+		return &url.Error{
+			Op:  urlErr.Op,
+			URL: r.URL(urlErr.URL),
+			Err: urlErr.Err,
+		}
+	}
+
+	return err
+}
+
+func (r *Redactor) redact(value string) string {
+	if len(r.hashKey) == 0 {
+		return redactedValue
+	}
+
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// WithLogRedactor configures the Redactor used to remove PHI from logs. By
+// default, production clients use a Redactor that allows
+// DefaultLogSafeQueryKeys and preview clients log everything. Pass nil to
+// disable redaction.
+func (h *HTTPClient) WithLogRedactor(redactor *Redactor) *HTTPClient {
+	h.redactor = redactor
+	h.redactorConfigured = true
+
+	return h
+}
+
+// logRedactor returns the Redactor used for logs.
+func (h *HTTPClient) logRedactor() *Redactor {
+	if h.redactorConfigured {
+		return h.redactor
+	}
+
+	if h.environment.Preview {
+		return nil
+	}
+
+	return defaultRedactor
+}
@@ -0,0 +1,114 @@
+package athenahealth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_URL(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRedactor("departmentid", "Limit")
+
+	assert.Equal(
+		"https://example.com/v1/1/patients?departmentid=1&dob=REDACTED&firstname=REDACTED&limit=10",
+		r.URL("https://example.com/v1/1/patients?firstname=Jane&dob=01%2F02%2F1980&departmentid=1&limit=10"),
+	)
+	assert.Equal("https://example.com/v1/1/patients/1", r.URL("https://example.com/v1/1/patients/1"))
+}
+
+func TestRedactor_URL_hash(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRedactor().WithHashKey([]byte("secret"))
+
+	u1, _ := url.Parse(r.URL("https://example.com/patients?firstname=Jane"))
+	u2, _ := url.Parse(r.URL("https://example.com/patients?firstname=Jane"))
+	u3, _ := url.Parse(r.URL("https://example.com/patients?firstname=John"))
+
+	assert.Regexp(`^hmac:[0-9a-f]{16}$`, u1.Query().Get("firstname"))
+	assert.Equal(u1.Query().Get("firstname"), u2.Query().Get("firstname"))
+	assert.NotEqual(u1.Query().Get("firstname"), u3.Query().Get("firstname"))
+}
+
+func TestRedactor_nil(t *testing.T) {
+	assert := assert.New(t)
+
+	var r *Redactor
+
+	assert.Equal("https://example.com/patients?firstname=Jane", r.URL("https://example.com/patients?firstname=Jane"))
+	assert.Equal("Jane Doe", r.Message("Jane Doe"))
+
+	err := errors.New("foo")
+	assert.Equal(err, r.Error(err))
+}
+
+func TestRedactor_Error(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRedactor()
+
+	apiErr := &APIError{AthenaError: "Invalid DOB", AthenaDetailedMessage: "Jane Doe 01/02/1980"}
+	redacted := r.Error(apiErr)
+	assert.NotContains(redacted.Error(), "Jane")
+	assert.Contains(redacted.Error(), "Invalid DOB")
+	assert.Equal("Jane Doe 01/02/1980", apiErr.AthenaDetailedMessage)
+
+	urlErr := &url.Error{Op: "Get", URL: "https://example.com/patients?firstname=Jane", Err: errors.New("connection reset")}
+	redacted = r.Error(urlErr)
+	assert.NotContains(redacted.Error(), "Jane")
+	assert.ErrorIs(redacted, urlErr.Err)
+}
+
+func TestHTTPClient_logRedactor(t *testing.T) {
+	assert := assert.New(t)
+
+	athenaClient := NewHTTPClient(&http.Client{}, testPracticeID, "", "")
+	assert.Nil(athenaClient.logRedactor())
+
+	athenaClient.WithPreview(false)
+	assert.Equal(defaultRedactor, athenaClient.logRedactor())
+
+	redactor := NewRedactor()
+	athenaClient.WithLogRedactor(redactor)
+	assert.Equal(redactor, athenaClient.logRedactor())
+
+	athenaClient.WithLogRedactor(nil)
+	assert.Nil(athenaClient.logRedactor())
+}
+
+func TestHTTPClient_request_redacts_logs(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid DOB","detailedmessage":"No patient Jane Doe"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	athenaClient.WithLogger(&logger).WithLogRedactor(NewRedactor(DefaultLogSafeQueryKeys...))
+
+	q := url.Values{
+		"firstname":    {"Jane"},
+		"departmentid": {"1"},
+	}
+
+	_, err := athenaClient.Get(context.Background(), "/patients", q, nil)
+	assert.Error(err)
+
+	assert.NotContains(buf.String(), "Jane")
+	assert.Contains(buf.String(), "departmentid=1")
+	assert.Contains(buf.String(), "Invalid DOB")
+}
@@ -0,0 +1,240 @@
+package athenahealth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 250 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.5
+)
+
+var errBodyNotReplayable = errors.New("request body cannot be replayed")
+
+// errRequestDone stops the encoding of a request body that will no longer be
+// read.
+var errRequestDone = errors.New("request done")
+
+// RetryPolicy controls how HTTPClient retries failed requests.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. The delay is
+	// multiplied by Multiplier for every following retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter is the fraction (0 to 1) of each delay that is randomized.
+	Jitter float64
+
+	// RetryableStatusCodes are the response status codes that are retried.
+	RetryableStatusCodes []int
+
+	// RetryNonIdempotent enables retries for requests that are not idempotent,
+	// such as POSTs and the PUT made by BookAppointment. Only enable this if
+	// duplicate writes are acceptable.
+	RetryNonIdempotent bool
+}
+
+// NewRetryPolicy returns a RetryPolicy that makes up to maxAttempts attempts for
+// idempotent requests that fail with a connection error, 429, 502, 503 or 504.
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         defaultRetryJitter,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type nonIdempotentContextKey struct{}
+
+// contextWithNonIdempotent marks requests made with ctx as not idempotent on
+// athena's side whatever their method, e.g. a PUT that books an appointment.
+// They are only retried if RetryNonIdempotent is set.
+func contextWithNonIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonIdempotentContextKey{}, true)
+}
+
+func nonIdempotentFromContext(ctx context.Context) bool {
+	nonIdempotent, _ := ctx.Value(nonIdempotentContextKey{}).(bool)
+
+	return nonIdempotent
+}
+
+// noRetryPolicy is used when a client is not configured with a RetryPolicy.
+var noRetryPolicy = &RetryPolicy{MaxAttempts: 1}
+
+// retryDelay reports whether the attempt that produced res and err should be
+// retried and how long to wait before doing so.
+func (r *RetryPolicy) retryDelay(ctx context.Context, method string, attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= r.MaxAttempts {
+		return 0, false
+	}
+
+	if !r.RetryNonIdempotent && (!isIdempotent(method) || nonIdempotentFromContext(ctx)) {
+		return 0, false
+	}
+
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	var retryAfter time.Duration
+
+	if res != nil {
+		if !slices.Contains(r.RetryableStatusCodes, res.StatusCode) {
+			return 0, false
+		}
+
+		retryAfter = parseRetryAfter(res.Header.Get("Retry-After"))
+	} else if !isRetryableError(err) {
+		return 0, false
+	}
+
+	delay := r.backoff(attempt)
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+
+	// Don't wait for a retry that can't complete before the deadline.
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// backoff returns the jittered exponential delay before retrying after attempt.
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(r.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= r.Multiplier
+	}
+
+	if r.MaxBackoff > 0 && delay > float64(r.MaxBackoff) {
+		delay = float64(r.MaxBackoff)
+	}
+
+	if r.Jitter > 0 {
+		delay -= delay * r.Jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header value in either delay-seconds or
+// HTTP-date format.
+func parseRetryAfter(v string) time.Duration {
+	if len(v) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// replayBody returns a reader that produces the same content as body did
+// before it was read.
+func replayBody(body io.Reader) (io.Reader, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+
+	case *formURLEncoderReader:
+		return b.replay()
+
+	case *rewindableReader:
+		_, err := b.Seek(b.offset, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+
+		return b, nil
+	}
+
+	return nil, errBodyNotReplayable
+}
+
+// closeBody stops producing body once the request is done. Bodies of requests
+// that were rejected before being sent, e.g. by an open circuit breaker, are
+// never read, and would otherwise block their encoder forever.
+func closeBody(body io.Reader) {
+	if b, ok := body.(*formURLEncoderReader); ok {
+		b.CloseWithError(errRequestDone)
+	}
+}
+
+// newReplayableBody wraps seekable bodies so they can be replayed on retry.
+func newReplayableBody(body io.Reader) io.Reader {
+	if rs, ok := body.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err == nil {
+			return &rewindableReader{ReadSeeker: rs, offset: offset}
+		}
+	}
+
+	return body
+}
@@ -0,0 +1,301 @@
+package athenahealth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy(maxAttempts int) *RetryPolicy {
+	retryPolicy := NewRetryPolicy(maxAttempts)
+	retryPolicy.InitialBackoff = time.Millisecond
+	retryPolicy.MaxBackoff = 10 * time.Millisecond
+
+	return retryPolicy
+}
+
+func TestHTTPClient_request_retry(t *testing.T) {
+	assert := assert.New(t)
+
+	var xRequestIDs []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		xRequestIDs = append(xRequestIDs, r.Header.Get(XRequestIDHeaderKey))
+
+		if len(xRequestIDs) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"msg":"Hello World!"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	var out map[string]string
+	res, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, &out)
+
+	assert.NotNil(res)
+	assert.NoError(err)
+	assert.Equal("Hello World!", out["msg"])
+	assert.Len(xRequestIDs, 3)
+	assert.Equal(xRequestIDs[0], xRequestIDs[1])
+	assert.Equal(xRequestIDs[0], xRequestIDs[2])
+}
+
+func TestHTTPClient_request_retry_max_attempts(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(2))
+
+	res, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+
+	assert.NotNil(res)
+	assert.IsType(&APIError{}, err)
+	assert.Equal(2, calls)
+}
+
+func TestHTTPClient_request_retry_non_retryable_status(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	_, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+
+	assert.Error(err)
+	assert.Equal(1, calls)
+}
+
+func TestHTTPClient_request_retry_non_idempotent(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	_, err := athenaClient.PostForm(context.Background(), "/", url.Values{"foo": {"bar"}}, nil)
+
+	assert.Error(err)
+	assert.Equal(1, calls)
+}
+
+func TestHTTPClient_BookAppointment_not_retried(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	// Booking is a PUT, but booking twice can double-book.
+	_, err := athenaClient.BookAppointment(context.Background(), "1", "2", nil)
+
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+	assert.Equal(1, calls)
+
+	// Other PUTs are still retried.
+	calls = 0
+
+	_, err = athenaClient.PutForm(context.Background(), "/appointments/2/checkin", url.Values{}, nil)
+
+	assert.Error(err)
+	assert.Equal(3, calls)
+}
+
+func TestHTTPClient_RescheduleAppointment_not_retried(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	_, err := athenaClient.RescheduleAppointment(context.Background(), 1, &RescheduleAppointmentOptions{NewAppointmentID: 2, PatientID: 3})
+
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+	assert.Equal(1, calls)
+}
+
+func TestHTTPClient_FreezeAppointmentSlot_not_retried(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(3))
+
+	err := athenaClient.FreezeAppointmentSlot(context.Background(), "1", nil)
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+	assert.Equal(1, calls)
+
+	calls = 0
+
+	err = athenaClient.UnfreezeAppointmentSlot(context.Background(), "1", nil)
+	assert.ErrorIs(err, ErrUpstreamUnavailable)
+	assert.Equal(1, calls)
+}
+
+func TestHTTPClient_request_retry_retry_after(t *testing.T) {
+	assert := assert.New(t)
+
+	var calledAt []time.Time
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calledAt = append(calledAt, time.Now())
+
+		if len(calledAt) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+	athenaClient.WithRetryPolicy(testRetryPolicy(2))
+
+	_, err := athenaClient.request(context.Background(), http.MethodGet, "/", nil, nil, nil)
+
+	assert.NoError(err)
+	assert.Len(calledAt, 2)
+	assert.GreaterOrEqual(calledAt[1].Sub(calledAt[0]), time.Second)
+}
+
+func TestHTTPClient_request_retry_replays_body(t *testing.T) {
+	assert := assert.New(t)
+
+	var bodies []string
+	h := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	retryPolicy := testRetryPolicy(2)
+	retryPolicy.RetryNonIdempotent = true
+	athenaClient.WithRetryPolicy(retryPolicy)
+
+	_, err := athenaClient.PutForm(context.Background(), "/", url.Values{"foo": {"bar"}}, nil)
+	assert.NoError(err)
+	assert.Equal([]string{"foo=bar", "foo=bar"}, bodies)
+
+	bodies = nil
+
+	fue := NewFormURLEncoder()
+	fue.AddString("foo", "bar")
+	fue.AddReader("file", bytes.NewReader(athenaTestImgBytes))
+
+	_, err = athenaClient.PostFormReader(context.Background(), "/", fue, nil)
+	assert.NoError(err)
+	assert.Len(bodies, 2)
+	assert.NotEmpty(bodies[0])
+	assert.Equal(bodies[0], bodies[1])
+}
+
+func TestHTTPClient_request_retry_body_not_replayable(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	retryPolicy := testRetryPolicy(3)
+	retryPolicy.RetryNonIdempotent = true
+	athenaClient.WithRetryPolicy(retryPolicy)
+
+	fue := NewFormURLEncoder()
+	fue.AddReader("file", io.NopCloser(strings.NewReader("foo")))
+
+	_, err := athenaClient.PostFormReader(context.Background(), "/", fue, nil)
+
+	assert.IsType(&APIError{}, err)
+	assert.Equal(1, calls)
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	assert := assert.New(t)
+
+	retryPolicy := NewRetryPolicy(5)
+	retryPolicy.Jitter = 0
+
+	assert.Equal(defaultRetryInitialBackoff, retryPolicy.backoff(1))
+	assert.Equal(2*defaultRetryInitialBackoff, retryPolicy.backoff(2))
+	assert.Equal(4*defaultRetryInitialBackoff, retryPolicy.backoff(3))
+	assert.Equal(defaultRetryMaxBackoff, retryPolicy.backoff(20))
+
+	retryPolicy.Jitter = 0.5
+
+	for range 100 {
+		delay := retryPolicy.backoff(1)
+		assert.LessOrEqual(delay, defaultRetryInitialBackoff)
+		assert.GreaterOrEqual(delay, defaultRetryInitialBackoff/2)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Zero(parseRetryAfter(""))
+	assert.Zero(parseRetryAfter("foo"))
+	assert.Zero(parseRetryAfter("-1"))
+	assert.Equal(2*time.Second, parseRetryAfter("2"))
+
+	d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.Greater(d, 58*time.Second)
+	assert.LessOrEqual(d, time.Minute)
+}
@@ -3,6 +3,7 @@ package stats
 import (
 	"net/url"
 	"regexp"
+	"strconv"
 
 	"github.com/DataDog/datadog-go/statsd"
 )
@@ -20,7 +21,7 @@ func NewDatadog(client statsd.ClientInterface) *Datadog {
 }
 
 func (d *Datadog) Request(method, path string) error {
-	path = cleanPath(path)
+	path = CleanPath(path)
 
 	return d.client.Incr("athenahealth.requests", []string{
 		"http_method:" + method,
@@ -36,7 +37,128 @@ func (d *Datadog) ResponseError() error {
 	return d.client.Incr("athenahealth.responses.error", []string{}, 1.0)
 }
 
-func cleanPath(path string) string {
+func (d *Datadog) RecordResponse(res Response) error {
+	tags := []string{
+		"http_method:" + res.Method,
+		"http_path:" + CleanPath(res.Path),
+		"practice_id:" + res.PracticeID,
+	}
+
+	err := d.client.Incr("athenahealth.requests", tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	err = d.client.Timing("athenahealth.request.duration", res.Duration, tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	err = d.client.Histogram("athenahealth.request.bytes", float64(res.RequestBytes), tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	// No response was received.
+	if res.StatusCode == 0 {
+		return d.client.Incr("athenahealth.responses.error", tags, 1.0)
+	}
+
+	tags = append(tags, "http_status_code:"+strconv.Itoa(res.StatusCode))
+
+	err = d.client.Histogram("athenahealth.response.bytes", float64(res.ResponseBytes), tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	if res.Success() {
+		return d.client.Incr("athenahealth.responses.success", tags, 1.0)
+	}
+
+	return d.client.Incr("athenahealth.responses.error", tags, 1.0)
+}
+
+func (d *Datadog) RecordRateLimitWait(wait RateLimitWait) error {
+	tags := []string{
+		"http_method:" + wait.Method,
+		"http_path:" + CleanPath(wait.Path),
+		"practice_id:" + wait.PracticeID,
+	}
+
+	err := d.client.Incr("athenahealth.rate_limit.waits", tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	return d.client.Timing("athenahealth.rate_limit.wait", wait.Wait, tags, 1.0)
+}
+
+func (d *Datadog) RecordRateLimitRate(rate RateLimitRate) error {
+	return d.client.Gauge("athenahealth.rate_limit.rate", rate.Rate, []string{"practice_id:" + rate.PracticeID}, 1.0)
+}
+
+func (d *Datadog) RecordTokenRefresh(refresh TokenRefresh) error {
+	tags := []string{
+		"rejected:" + strconv.FormatBool(refresh.Rejected),
+		"success:" + strconv.FormatBool(refresh.Success),
+	}
+
+	err := d.client.Incr("athenahealth.token.refreshes", tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	return d.client.Timing("athenahealth.token.refresh.duration", refresh.Duration, tags, 1.0)
+}
+
+func (d *Datadog) RecordCircuitBreakerStateChange(change CircuitBreakerStateChange) error {
+	tags := []string{
+		"scope:" + change.Scope,
+		"practice_id:" + change.PracticeID,
+		"group:" + change.Group,
+		"from:" + change.From,
+		"to:" + change.To,
+	}
+
+	return d.client.Incr("athenahealth.circuit_breaker.state_changes", tags, 1.0)
+}
+
+func (d *Datadog) RecordBulkheadAcquire(acquire BulkheadAcquire) error {
+	tags := []string{
+		"group:" + acquire.Group,
+	}
+
+	err := d.client.Timing("athenahealth.bulkhead.wait", acquire.Wait, tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	if acquire.Rejected {
+		err = d.client.Incr("athenahealth.bulkhead.rejected", tags, 1.0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.recordBulkheadGauges(tags, acquire.InFlight, acquire.Queued)
+}
+
+func (d *Datadog) RecordBulkheadRelease(release BulkheadRelease) error {
+	return d.recordBulkheadGauges([]string{"group:" + release.Group}, release.InFlight, release.Queued)
+}
+
+func (d *Datadog) recordBulkheadGauges(tags []string, inFlight, queued int) error {
+	err := d.client.Gauge("athenahealth.bulkhead.in_flight", float64(inFlight), tags, 1.0)
+	if err != nil {
+		return err
+	}
+
+	return d.client.Gauge("athenahealth.bulkhead.queued", float64(queued), tags, 1.0)
+}
+
+// CleanPath removes the query string from path and replaces numeric IDs with
+// ":id:" so paths can be used as low-cardinality tags.
+func CleanPath(path string) string {
 	u, err := url.Parse(path)
 	if err != nil {
 		return ""
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-go/statsd"
 	"github.com/stretchr/testify/assert"
@@ -9,13 +10,58 @@ import (
 
 type mockClient struct {
 	statsd.ClientInterface
-	incrFn func(name string, tags []string, rate float64) error
+	incrFn      func(name string, tags []string, rate float64) error
+	timingFn    func(name string, value time.Duration, tags []string, rate float64) error
+	histogramFn func(name string, value float64, tags []string, rate float64) error
+	gaugeFn     func(name string, value float64, tags []string, rate float64) error
 }
 
 func (m *mockClient) Incr(name string, tags []string, rate float64) error {
 	return m.incrFn(name, tags, rate)
 }
 
+func (m *mockClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	return m.timingFn(name, value, tags, rate)
+}
+
+func (m *mockClient) Histogram(name string, value float64, tags []string, rate float64) error {
+	return m.histogramFn(name, value, tags, rate)
+}
+
+func (m *mockClient) Gauge(name string, value float64, tags []string, rate float64) error {
+	return m.gaugeFn(name, value, tags, rate)
+}
+
+// recordingClient returns a mockClient that records every metric it receives
+// by name.
+func recordingClient() (*mockClient, map[string][]string, map[string]float64) {
+	tags := map[string][]string{}
+	values := map[string]float64{}
+
+	return &mockClient{
+		incrFn: func(name string, t []string, rate float64) error {
+			tags[name] = t
+			values[name]++
+			return nil
+		},
+		timingFn: func(name string, value time.Duration, t []string, rate float64) error {
+			tags[name] = t
+			values[name] = float64(value)
+			return nil
+		},
+		histogramFn: func(name string, value float64, t []string, rate float64) error {
+			tags[name] = t
+			values[name] = value
+			return nil
+		},
+		gaugeFn: func(name string, value float64, t []string, rate float64) error {
+			tags[name] = t
+			values[name] = value
+			return nil
+		},
+	}, tags, values
+}
+
 func TestDatadog_Request(t *testing.T) {
 	assert := assert.New(t)
 
@@ -33,11 +79,157 @@ func TestDatadog_Request(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestDatadog_RecordResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordResponse(Response{
+		Method:        "GET",
+		Path:          "/patients/123?foo=bar",
+		PracticeID:    "195900",
+		StatusCode:    404,
+		Duration:      time.Second,
+		RequestBytes:  10,
+		ResponseBytes: 20,
+	})
+	assert.NoError(err)
+
+	assert.Equal([]string{"http_method:GET", "http_path:/patients/:id:", "practice_id:195900"}, tags["athenahealth.requests"])
+	assert.Equal([]string{"http_method:GET", "http_path:/patients/:id:", "practice_id:195900", "http_status_code:404"}, tags["athenahealth.responses.error"])
+	assert.Equal(float64(time.Second), values["athenahealth.request.duration"])
+	assert.Equal(float64(10), values["athenahealth.request.bytes"])
+	assert.Equal(float64(20), values["athenahealth.response.bytes"])
+	assert.NotContains(values, "athenahealth.responses.success")
+}
+
+func TestDatadog_RecordResponse_no_response(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordResponse(Response{
+		Method: "GET",
+		Path:   "/patients/123",
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(1), values["athenahealth.responses.error"])
+	assert.NotContains(tags["athenahealth.responses.error"], "http_status_code:0")
+	assert.NotContains(values, "athenahealth.response.bytes")
+}
+
+func TestDatadog_RecordRateLimitWait(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordRateLimitWait(RateLimitWait{
+		Method:     "GET",
+		Path:       "/patients/123",
+		PracticeID: "195900",
+		Wait:       time.Second,
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(1), values["athenahealth.rate_limit.waits"])
+	assert.Equal(float64(time.Second), values["athenahealth.rate_limit.wait"])
+	assert.Equal([]string{"http_method:GET", "http_path:/patients/:id:", "practice_id:195900"}, tags["athenahealth.rate_limit.wait"])
+}
+
+func TestDatadog_RecordRateLimitRate(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordRateLimitRate(RateLimitRate{PracticeID: "195900", Rate: 12.5})
+	assert.NoError(err)
+
+	assert.Equal(12.5, values["athenahealth.rate_limit.rate"])
+	assert.Equal([]string{"practice_id:195900"}, tags["athenahealth.rate_limit.rate"])
+}
+
+func TestDatadog_RecordTokenRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordTokenRefresh(TokenRefresh{
+		Rejected: true,
+		Success:  true,
+		Duration: time.Second,
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(1), values["athenahealth.token.refreshes"])
+	assert.Equal(float64(time.Second), values["athenahealth.token.refresh.duration"])
+	assert.Equal([]string{"rejected:true", "success:true"}, tags["athenahealth.token.refreshes"])
+}
+
+func TestDatadog_RecordCircuitBreakerStateChange(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordCircuitBreakerStateChange(CircuitBreakerStateChange{
+		Scope:      "group:documents",
+		PracticeID: "195900",
+		Group:      "documents",
+		From:       "closed",
+		To:         "open",
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(1), values["athenahealth.circuit_breaker.state_changes"])
+	assert.Equal([]string{"scope:group:documents", "practice_id:195900", "group:documents", "from:closed", "to:open"}, tags["athenahealth.circuit_breaker.state_changes"])
+}
+
+func TestDatadog_RecordBulkheadAcquire(t *testing.T) {
+	assert := assert.New(t)
+
+	client, tags, values := recordingClient()
+
+	datadog := NewDatadog(client)
+
+	err := datadog.RecordBulkheadAcquire(BulkheadAcquire{
+		Group:    "documents",
+		Wait:     time.Second,
+		Rejected: true,
+		InFlight: 4,
+		Queued:   2,
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(time.Second), values["athenahealth.bulkhead.wait"])
+	assert.Equal(float64(1), values["athenahealth.bulkhead.rejected"])
+	assert.Equal(float64(4), values["athenahealth.bulkhead.in_flight"])
+	assert.Equal(float64(2), values["athenahealth.bulkhead.queued"])
+	assert.Equal([]string{"group:documents"}, tags["athenahealth.bulkhead.rejected"])
+
+	err = datadog.RecordBulkheadRelease(BulkheadRelease{Group: "documents", InFlight: 3, Queued: 1})
+	assert.NoError(err)
+
+	assert.Equal(float64(3), values["athenahealth.bulkhead.in_flight"])
+	assert.Equal(float64(1), values["athenahealth.bulkhead.queued"])
+}
+
 func TestRemoveIDsFromPath(t *testing.T) {
 	assert := assert.New(t)
 
-	assert.Equal("/patients/:id:", cleanPath("/patients/123"))
-	assert.Equal("/patients/:id:", cleanPath("/patients/123?foo=bar"))
-	assert.Equal("/patients/:id:/foo/:id:", cleanPath("/patients/123/foo/1"))
-	assert.Equal("/patients/:id:/foo/:id:/", cleanPath("/patients/123/foo/1/"))
+	assert.Equal("/patients/:id:", CleanPath("/patients/123"))
+	assert.Equal("/patients/:id:", CleanPath("/patients/123?foo=bar"))
+	assert.Equal("/patients/:id:/foo/:id:", CleanPath("/patients/123/foo/1"))
+	assert.Equal("/patients/:id:/foo/:id:/", CleanPath("/patients/123/foo/1/"))
 }
@@ -18,3 +18,31 @@ func (d *Default) ResponseSuccess() error {
 func (d *Default) ResponseError() error {
 	return nil
 }
+
+func (d *Default) RecordResponse(res Response) error {
+	return nil
+}
+
+func (d *Default) RecordRateLimitWait(wait RateLimitWait) error {
+	return nil
+}
+
+func (d *Default) RecordRateLimitRate(rate RateLimitRate) error {
+	return nil
+}
+
+func (d *Default) RecordTokenRefresh(refresh TokenRefresh) error {
+	return nil
+}
+
+func (d *Default) RecordCircuitBreakerStateChange(change CircuitBreakerStateChange) error {
+	return nil
+}
+
+func (d *Default) RecordBulkheadAcquire(acquire BulkheadAcquire) error {
+	return nil
+}
+
+func (d *Default) RecordBulkheadRelease(release BulkheadRelease) error {
+	return nil
+}
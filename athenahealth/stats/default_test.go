@@ -29,3 +29,51 @@ func TestDefault_ResponseError(t *testing.T) {
 	err := stats.ResponseError()
 	assert.NoError(err)
 }
+
+func TestDefault_RecordResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	err := stats.RecordResponse(Response{})
+	assert.NoError(err)
+}
+
+func TestDefault_RecordRateLimitWait(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	err := stats.RecordRateLimitWait(RateLimitWait{})
+	assert.NoError(err)
+}
+
+func TestDefault_RecordRateLimitRate(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	err := stats.RecordRateLimitRate(RateLimitRate{})
+	assert.NoError(err)
+}
+
+func TestDefault_RecordTokenRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	err := stats.RecordTokenRefresh(TokenRefresh{})
+	assert.NoError(err)
+}
+
+func TestDefault_RecordCircuitBreakerStateChange(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	err := stats.RecordCircuitBreakerStateChange(CircuitBreakerStateChange{})
+	assert.NoError(err)
+}
+
+func TestDefault_RecordBulkhead(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewDefault()
+	assert.NoError(stats.RecordBulkheadAcquire(BulkheadAcquire{}))
+	assert.NoError(stats.RecordBulkheadRelease(BulkheadRelease{}))
+}
@@ -0,0 +1,279 @@
+package stats
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prometheusNamespace = "athenahealth"
+
+// Prometheus records stats as Prometheus metrics. Paths are templated with
+// CleanPath so label cardinality stays bounded.
+type Prometheus struct {
+	requests            *prometheus.CounterVec
+	responses           *prometheus.CounterVec
+	requestDuration     *prometheus.HistogramVec
+	requestSize         *prometheus.HistogramVec
+	responseSize        *prometheus.HistogramVec
+	rateLimitWaits      *prometheus.HistogramVec
+	rateLimitRate       *prometheus.GaugeVec
+	tokenRefreshes      *prometheus.CounterVec
+	tokenRefreshLatency *prometheus.HistogramVec
+	circuitStateChanges *prometheus.CounterVec
+	circuitOpen         *prometheus.GaugeVec
+	bulkheadWaits       *prometheus.HistogramVec
+	bulkheadRejections  *prometheus.CounterVec
+	bulkheadInFlight    *prometheus.GaugeVec
+	bulkheadQueued      *prometheus.GaugeVec
+}
+
+// NewPrometheus creates the athenahealth metrics and registers them on reg.
+// Metrics that are already registered on reg are reused, so several
+// Prometheus values can share a registry.
+func NewPrometheus(reg prometheus.Registerer) (*Prometheus, error) {
+	endpointLabels := []string{"method", "path", "practice_id"}
+	sizeBuckets := prometheus.ExponentialBuckets(256, 4, 8)
+
+	p := &Prometheus{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "requests_total",
+			Help:      "Requests sent to the athenahealth API.",
+		}, endpointLabels),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "responses_total",
+			Help:      "Responses received from the athenahealth API. status_code is empty if no response was received.",
+		}, append(endpointLabels, "status_code", "result")),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests sent to the athenahealth API.",
+			Buckets:   prometheus.DefBuckets,
+		}, endpointLabels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "request_size_bytes",
+			Help:      "Size of request bodies sent to the athenahealth API.",
+			Buckets:   sizeBuckets,
+		}, endpointLabels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "response_size_bytes",
+			Help:      "Size of response bodies received from the athenahealth API.",
+			Buckets:   sizeBuckets,
+		}, endpointLabels),
+		rateLimitWaits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "rate_limit_wait_seconds",
+			Help:      "Time requests spent waiting on the rate limiter.",
+			Buckets:   prometheus.DefBuckets,
+		}, endpointLabels),
+		rateLimitRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Name:      "rate_limit_rate",
+			Help:      "Requests per second allowed by the adaptive rate limiter.",
+		}, []string{"practice_id"}),
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "token_refreshes_total",
+			Help:      "Requests for a new athenahealth API token.",
+		}, []string{"rejected", "success"}),
+		tokenRefreshLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "token_refresh_duration_seconds",
+			Help:      "Latency of requests for a new athenahealth API token.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"rejected", "success"}),
+		circuitStateChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "circuit_breaker_state_changes_total",
+			Help:      "Circuit breaker state changes.",
+		}, []string{"scope", "group", "from", "to"}),
+		circuitOpen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Name:      "circuit_breaker_open",
+			Help:      "1 if the circuit breaker is rejecting requests, 0 otherwise.",
+		}, []string{"scope", "group"}),
+		bulkheadWaits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "bulkhead_wait_seconds",
+			Help:      "Time requests spent waiting for a bulkhead slot.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"group"}),
+		bulkheadRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "bulkhead_rejections_total",
+			Help:      "Requests that gave up waiting for a bulkhead slot.",
+		}, []string{"group"}),
+		bulkheadInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Name:      "bulkhead_in_flight",
+			Help:      "Requests holding a bulkhead slot.",
+		}, []string{"group"}),
+		bulkheadQueued: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Name:      "bulkhead_queued",
+			Help:      "Requests waiting for a bulkhead slot.",
+		}, []string{"group"}),
+	}
+
+	var err error
+
+	registerCounter := func(c **prometheus.CounterVec) {
+		if err == nil {
+			*c, err = register(reg, *c)
+		}
+	}
+	registerHistogram := func(h **prometheus.HistogramVec) {
+		if err == nil {
+			*h, err = register(reg, *h)
+		}
+	}
+
+	registerGauge := func(g **prometheus.GaugeVec) {
+		if err == nil {
+			*g, err = register(reg, *g)
+		}
+	}
+
+	registerCounter(&p.requests)
+	registerCounter(&p.responses)
+	registerHistogram(&p.requestDuration)
+	registerHistogram(&p.requestSize)
+	registerHistogram(&p.responseSize)
+	registerHistogram(&p.rateLimitWaits)
+	registerGauge(&p.rateLimitRate)
+	registerCounter(&p.tokenRefreshes)
+	registerHistogram(&p.tokenRefreshLatency)
+	registerCounter(&p.circuitStateChanges)
+	registerGauge(&p.circuitOpen)
+	registerHistogram(&p.bulkheadWaits)
+	registerCounter(&p.bulkheadRejections)
+	registerGauge(&p.bulkheadInFlight)
+	registerGauge(&p.bulkheadQueued)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// register registers c on reg, returning the collector that is already
+// registered if there is one.
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) (C, error) {
+	err := reg.Register(c)
+	if err == nil {
+		return c, nil
+	}
+
+	are := prometheus.AlreadyRegisteredError{}
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			return existing, nil
+		}
+	}
+
+	return c, err
+}
+
+func (p *Prometheus) Request(method, path string) error {
+	p.requests.WithLabelValues(method, CleanPath(path), "").Inc()
+
+	return nil
+}
+
+func (p *Prometheus) ResponseSuccess() error {
+	p.responses.WithLabelValues("", "", "", "", "success").Inc()
+
+	return nil
+}
+
+func (p *Prometheus) ResponseError() error {
+	p.responses.WithLabelValues("", "", "", "", "error").Inc()
+
+	return nil
+}
+
+func (p *Prometheus) RecordResponse(res Response) error {
+	path := CleanPath(res.Path)
+
+	p.requests.WithLabelValues(res.Method, path, res.PracticeID).Inc()
+	p.requestDuration.WithLabelValues(res.Method, path, res.PracticeID).Observe(res.Duration.Seconds())
+	p.requestSize.WithLabelValues(res.Method, path, res.PracticeID).Observe(float64(res.RequestBytes))
+
+	result := "error"
+	if res.Success() {
+		result = "success"
+	}
+
+	// No response was received.
+	if res.StatusCode == 0 {
+		p.responses.WithLabelValues(res.Method, path, res.PracticeID, "", result).Inc()
+
+		return nil
+	}
+
+	p.responses.WithLabelValues(res.Method, path, res.PracticeID, strconv.Itoa(res.StatusCode), result).Inc()
+	p.responseSize.WithLabelValues(res.Method, path, res.PracticeID).Observe(float64(res.ResponseBytes))
+
+	return nil
+}
+
+func (p *Prometheus) RecordRateLimitWait(wait RateLimitWait) error {
+	p.rateLimitWaits.WithLabelValues(wait.Method, CleanPath(wait.Path), wait.PracticeID).Observe(wait.Wait.Seconds())
+
+	return nil
+}
+
+func (p *Prometheus) RecordRateLimitRate(rate RateLimitRate) error {
+	p.rateLimitRate.WithLabelValues(rate.PracticeID).Set(rate.Rate)
+
+	return nil
+}
+
+func (p *Prometheus) RecordTokenRefresh(refresh TokenRefresh) error {
+	rejected := strconv.FormatBool(refresh.Rejected)
+	success := strconv.FormatBool(refresh.Success)
+
+	p.tokenRefreshes.WithLabelValues(rejected, success).Inc()
+	p.tokenRefreshLatency.WithLabelValues(rejected, success).Observe(refresh.Duration.Seconds())
+
+	return nil
+}
+
+func (p *Prometheus) RecordCircuitBreakerStateChange(change CircuitBreakerStateChange) error {
+	p.circuitStateChanges.WithLabelValues(change.Scope, change.Group, change.From, change.To).Inc()
+
+	open := 0.0
+	if change.To == "open" {
+		open = 1
+	}
+
+	p.circuitOpen.WithLabelValues(change.Scope, change.Group).Set(open)
+
+	return nil
+}
+
+func (p *Prometheus) RecordBulkheadAcquire(acquire BulkheadAcquire) error {
+	p.bulkheadWaits.WithLabelValues(acquire.Group).Observe(acquire.Wait.Seconds())
+
+	if acquire.Rejected {
+		p.bulkheadRejections.WithLabelValues(acquire.Group).Inc()
+	}
+
+	p.bulkheadInFlight.WithLabelValues(acquire.Group).Set(float64(acquire.InFlight))
+	p.bulkheadQueued.WithLabelValues(acquire.Group).Set(float64(acquire.Queued))
+
+	return nil
+}
+
+func (p *Prometheus) RecordBulkheadRelease(release BulkheadRelease) error {
+	p.bulkheadInFlight.WithLabelValues(release.Group).Set(float64(release.InFlight))
+	p.bulkheadQueued.WithLabelValues(release.Group).Set(float64(release.Queued))
+
+	return nil
+}
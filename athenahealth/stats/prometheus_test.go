@@ -0,0 +1,197 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus_RecordResponse(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := prometheus.NewRegistry()
+
+	p, err := NewPrometheus(reg)
+	assert.NoError(err)
+
+	err = p.RecordResponse(Response{
+		Method:        "GET",
+		Path:          "/patients/123?foo=bar",
+		PracticeID:    "195900",
+		StatusCode:    404,
+		Duration:      time.Second,
+		RequestBytes:  10,
+		ResponseBytes: 20,
+	})
+	assert.NoError(err)
+
+	err = p.RecordResponse(Response{
+		Method:     "GET",
+		Path:       "/patients/456",
+		PracticeID: "195900",
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(2), testutil.ToFloat64(p.requests.WithLabelValues("GET", "/patients/:id:", "195900")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.responses.WithLabelValues("GET", "/patients/:id:", "195900", "404", "error")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.responses.WithLabelValues("GET", "/patients/:id:", "195900", "", "error")))
+
+	expected := `
+# HELP athenahealth_response_size_bytes Size of response bodies received from the athenahealth API.
+# TYPE athenahealth_response_size_bytes histogram
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="256"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="1024"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="4096"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="16384"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="65536"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="262144"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="1.048576e+06"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="4.194304e+06"} 1
+athenahealth_response_size_bytes_bucket{method="GET",path="/patients/:id:",practice_id="195900",le="+Inf"} 1
+athenahealth_response_size_bytes_sum{method="GET",path="/patients/:id:",practice_id="195900"} 20
+athenahealth_response_size_bytes_count{method="GET",path="/patients/:id:",practice_id="195900"} 1
+`
+	assert.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), "athenahealth_response_size_bytes"))
+
+	assert.Equal(1, testutil.CollectAndCount(p.requestDuration))
+	assert.Equal(1, testutil.CollectAndCount(p.requestSize))
+}
+
+func TestPrometheus_RecordRateLimitWait(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := prometheus.NewRegistry()
+
+	p, err := NewPrometheus(reg)
+	assert.NoError(err)
+
+	err = p.RecordRateLimitWait(RateLimitWait{
+		Method:     "POST",
+		Path:       "/appointments/123",
+		PracticeID: "195900",
+		Wait:       time.Second,
+	})
+	assert.NoError(err)
+
+	count, err := testutil.GatherAndCount(reg, "athenahealth_rate_limit_wait_seconds")
+	assert.NoError(err)
+	assert.Equal(1, count)
+}
+
+func TestPrometheus_RecordTokenRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := prometheus.NewRegistry()
+
+	p, err := NewPrometheus(reg)
+	assert.NoError(err)
+
+	err = p.RecordTokenRefresh(TokenRefresh{
+		Rejected: true,
+		Success:  true,
+		Duration: time.Second,
+	})
+	assert.NoError(err)
+
+	assert.Equal(float64(1), testutil.ToFloat64(p.tokenRefreshes.WithLabelValues("true", "true")))
+	assert.Equal(1, testutil.CollectAndCount(p.tokenRefreshLatency))
+}
+
+func TestPrometheus_legacy(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := NewPrometheus(prometheus.NewRegistry())
+	assert.NoError(err)
+
+	assert.NoError(p.Request("GET", "/patients/123"))
+	assert.NoError(p.ResponseSuccess())
+	assert.NoError(p.ResponseError())
+
+	assert.Equal(float64(1), testutil.ToFloat64(p.requests.WithLabelValues("GET", "/patients/:id:", "")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.responses.WithLabelValues("", "", "", "", "success")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.responses.WithLabelValues("", "", "", "", "error")))
+}
+
+func TestPrometheus_RecordCircuitBreakerStateChange(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := NewPrometheus(prometheus.NewRegistry())
+	assert.NoError(err)
+
+	change := CircuitBreakerStateChange{Scope: "group:documents", Group: "documents", From: "closed", To: "open"}
+	assert.NoError(p.RecordCircuitBreakerStateChange(change))
+
+	assert.Equal(float64(1), testutil.ToFloat64(p.circuitStateChanges.WithLabelValues("group:documents", "documents", "closed", "open")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.circuitOpen.WithLabelValues("group:documents", "documents")))
+
+	change = CircuitBreakerStateChange{Scope: "group:documents", Group: "documents", From: "open", To: "half-open"}
+	assert.NoError(p.RecordCircuitBreakerStateChange(change))
+
+	assert.Equal(float64(0), testutil.ToFloat64(p.circuitOpen.WithLabelValues("group:documents", "documents")))
+}
+
+func TestPrometheus_RecordRateLimitRate(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := NewPrometheus(prometheus.NewRegistry())
+	assert.NoError(err)
+
+	assert.NoError(p.RecordRateLimitRate(RateLimitRate{PracticeID: "195900", Rate: 50}))
+	assert.NoError(p.RecordRateLimitRate(RateLimitRate{PracticeID: "195900", Rate: 25}))
+
+	assert.Equal(float64(25), testutil.ToFloat64(p.rateLimitRate.WithLabelValues("195900")))
+}
+
+func TestPrometheus_RecordBulkhead(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := NewPrometheus(prometheus.NewRegistry())
+	assert.NoError(err)
+
+	assert.NoError(p.RecordBulkheadAcquire(BulkheadAcquire{Group: "documents", Wait: time.Second, InFlight: 2}))
+	assert.NoError(p.RecordBulkheadAcquire(BulkheadAcquire{Group: "documents", Wait: time.Second, Rejected: true, InFlight: 2, Queued: 1}))
+
+	assert.Equal(float64(1), testutil.ToFloat64(p.bulkheadRejections.WithLabelValues("documents")))
+	assert.Equal(float64(2), testutil.ToFloat64(p.bulkheadInFlight.WithLabelValues("documents")))
+	assert.Equal(float64(1), testutil.ToFloat64(p.bulkheadQueued.WithLabelValues("documents")))
+
+	assert.NoError(p.RecordBulkheadRelease(BulkheadRelease{Group: "documents", InFlight: 1}))
+
+	assert.Equal(float64(1), testutil.ToFloat64(p.bulkheadInFlight.WithLabelValues("documents")))
+	assert.Equal(float64(0), testutil.ToFloat64(p.bulkheadQueued.WithLabelValues("documents")))
+}
+
+func TestNewPrometheus_shared_registry(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := prometheus.NewRegistry()
+
+	p1, err := NewPrometheus(reg)
+	assert.NoError(err)
+
+	p2, err := NewPrometheus(reg)
+	assert.NoError(err)
+
+	p1.RecordResponse(Response{Method: "GET", Path: "/patients/1", StatusCode: 200})
+	p2.RecordResponse(Response{Method: "GET", Path: "/patients/2", StatusCode: 200})
+
+	assert.Equal(float64(2), testutil.ToFloat64(p1.requests.WithLabelValues("GET", "/patients/:id:", "")))
+}
+
+func TestNewPrometheus_conflict(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Name:      "requests_total",
+		Help:      "Something else.",
+	}))
+
+	_, err := NewPrometheus(reg)
+	assert.Error(err)
+}
@@ -0,0 +1,82 @@
+package stats
+
+import "time"
+
+// Response describes a single request attempt made to the athenahealth API.
+type Response struct {
+	Method     string
+	Path       string
+	PracticeID string
+
+	// StatusCode is zero if no response was received.
+	StatusCode int
+	Duration   time.Duration
+
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// Success reports whether the attempt received a 2xx response.
+func (r Response) Success() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// RateLimitWait describes time a request spent waiting on the rate limiter.
+type RateLimitWait struct {
+	Method     string
+	Path       string
+	PracticeID string
+
+	Wait time.Duration
+}
+
+// RateLimitRate describes the effective rate of an adaptive rate limiter after
+// it adjusted to a response.
+type RateLimitRate struct {
+	PracticeID string
+	// Rate is in requests per second.
+	Rate float64
+}
+
+// TokenRefresh describes a request for a new token.
+type TokenRefresh struct {
+	// Rejected is true if the previous token was rejected by athena rather
+	// than missing or expired.
+	Rejected bool
+	Success  bool
+	Duration time.Duration
+}
+
+// CircuitBreakerStateChange describes a circuit breaker changing state.
+type CircuitBreakerStateChange struct {
+	// Scope identifies the circuit, e.g. a practice and endpoint group.
+	Scope      string
+	PracticeID string
+	Group      string
+
+	From string
+	To   string
+}
+
+// BulkheadAcquire describes a request waiting for a slot in its endpoint
+// group's bulkhead.
+type BulkheadAcquire struct {
+	Group string
+	Wait  time.Duration
+	// Rejected is true if the request gave up waiting and was not sent.
+	Rejected bool
+
+	// InFlight and Queued are the number of requests to the group holding and
+	// waiting for a slot afterwards.
+	InFlight int
+	Queued   int
+}
+
+// BulkheadRelease describes a request freeing its slot in its endpoint group's
+// bulkhead.
+type BulkheadRelease struct {
+	Group string
+
+	InFlight int
+	Queued   int
+}
@@ -41,3 +41,13 @@ func (d *Default) Set(ctx context.Context, token string, expiresAt time.Time) er
 
 	return nil
 }
+
+func (d *Default) Invalidate(ctx context.Context) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.token = ""
+	d.expiresAt = time.Time{}
+
+	return nil
+}
@@ -64,3 +64,19 @@ func TestDefault_Set(t *testing.T) {
 	assert.True(expiresAt.Equal(cacher.expiresAt))
 	assert.NoError(err)
 }
+
+func TestDefault_Invalidate(t *testing.T) {
+	assert := assert.New(t)
+
+	cacher := NewDefault()
+	cacher.token = "foo"
+	cacher.expiresAt = time.Now().Add(time.Minute * 1)
+
+	err := cacher.Invalidate(context.Background())
+	assert.NoError(err)
+
+	token, err := cacher.Get(context.Background())
+
+	assert.Empty(token)
+	assert.True(errors.Is(err, ErrTokenNotExist))
+}
@@ -85,3 +85,15 @@ func (f *File) Set(ctx context.Context, token string, expiresAt time.Time) error
 
 	return nil
 }
+
+func (f *File) Invalidate(ctx context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	err := os.Remove(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -104,3 +104,30 @@ func TestFile_Set(t *testing.T) {
 	assert.Equal(token, c.Token)
 	assert.True(expiresAt.Equal(c.ExpiresAt))
 }
+
+func TestFile_Invalidate(t *testing.T) {
+	assert := assert.New(t)
+
+	file, err := os.CreateTemp("", "go-athenahealth_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	cacher := NewFile(file.Name())
+
+	err = cacher.Set(context.Background(), "foo", time.Now().Add(time.Minute*1))
+	assert.NoError(err)
+
+	err = cacher.Invalidate(context.Background())
+	assert.NoError(err)
+
+	token, err := cacher.Get(context.Background())
+
+	assert.Empty(token)
+	assert.True(errors.Is(err, ErrTokenNotExist))
+
+	// Invalidating a missing file is not an error.
+	os.Remove(file.Name())
+	assert.NoError(cacher.Invalidate(context.Background()))
+}
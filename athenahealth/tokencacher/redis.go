@@ -50,3 +50,9 @@ func (r *Redis) Set(ctx context.Context, token string, expiresAt time.Time) erro
 
 	return err
 }
+
+func (r *Redis) Invalidate(ctx context.Context) error {
+	_, err := r.client.Del(ctx, r.key).Result()
+
+	return err
+}
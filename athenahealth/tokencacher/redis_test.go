@@ -79,3 +79,32 @@ func TestRedis_Set(t *testing.T) {
 	assert.Equal(expectedToken, token)
 	assert.True(time.Now().Add(time.Second * ttl).After(time.Now()))
 }
+
+func TestRedis_Invalidate(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	s.Set(RedisDefaultKey, "foo")
+
+	cacher := NewRedis(redis.NewClient(&redis.Options{
+		Addr: s.Addr(),
+	}), "")
+
+	// The token isn't invalidated once ctx is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = cacher.Invalidate(ctx)
+	assert.ErrorIs(err, context.Canceled)
+	assert.True(s.Exists(RedisDefaultKey))
+
+	err = cacher.Invalidate(context.Background())
+	assert.NoError(err)
+
+	assert.False(s.Exists(RedisDefaultKey))
+}
@@ -8,6 +8,11 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -16,6 +21,12 @@ const (
 
 	// ProdAuthURL is the URL used to authenticate in the production environment.
 	ProdAuthURL = "https://api.platform.athenahealth.com/oauth2/v1/token"
+
+	// DefaultScope is the OAuth scope requested by default.
+	DefaultScope = "athena/service/Athenanet.MDP.*"
+
+	// tracerName is the instrumentation scope name used for spans created by this package.
+	tracerName = "github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
 )
 
 type Default struct {
@@ -25,6 +36,9 @@ type Default struct {
 	secret   string
 
 	authURL string
+	scope   string
+
+	tracer trace.Tracer
 }
 
 func NewDefault(httpClient *http.Client, clientID, secret string, preview bool) *Default {
@@ -33,6 +47,9 @@ func NewDefault(httpClient *http.Client, clientID, secret string, preview bool)
 
 		clientID: clientID,
 		secret:   secret,
+		scope:    DefaultScope,
+
+		tracer: otel.GetTracerProvider().Tracer(tracerName),
 	}
 
 	if preview {
@@ -49,10 +66,50 @@ type authResponse struct {
 	ExpiresIn   json.Number `json:"expires_in"`
 }
 
+// WithTracerProvider configures the OpenTelemetry TracerProvider used to trace
+// token requests. By default the global TracerProvider is used.
+func (d *Default) WithTracerProvider(tracerProvider trace.TracerProvider) *Default {
+	d.tracer = tracerProvider.Tracer(tracerName)
+
+	return d
+}
+
+// WithAuthURL configures the OAuth token URL, e.g. to authenticate through a
+// proxy.
+func (d *Default) WithAuthURL(authURL string) *Default {
+	d.authURL = authURL
+
+	return d
+}
+
+// WithScope configures the OAuth scope requested with tokens. By default
+// DefaultScope is requested.
+func (d *Default) WithScope(scope string) *Default {
+	d.scope = scope
+
+	return d
+}
+
 func (d *Default) Provide(ctx context.Context) (string, time.Time, error) {
+	ctx, span := d.tracer.Start(ctx, "athenahealth token",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("url.full", d.authURL)),
+	)
+	defer span.End()
+
+	token, expiresAt, err := d.provide(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return token, expiresAt, err
+}
+
+func (d *Default) provide(ctx context.Context) (string, time.Time, error) {
 	vals := url.Values{
 		"grant_type": {"client_credentials"},
-		"scope":      {"athena/service/Athenanet.MDP.*"},
+		"scope":      {d.scope},
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", d.authURL, bytes.NewBufferString(vals.Encode()))
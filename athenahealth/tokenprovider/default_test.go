@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNewDefault(t *testing.T) {
@@ -24,6 +27,7 @@ func TestNewDefault(t *testing.T) {
 	assert.Equal(key, p.clientID)
 	assert.Equal(secret, p.secret)
 	assert.Equal(PreviewAuthURL, p.authURL)
+	assert.Equal(DefaultScope, p.scope)
 
 	preview = false
 	p = NewDefault(&http.Client{}, "", "", preview)
@@ -56,3 +60,46 @@ func TestDefault_Provide(t *testing.T) {
 	assert.True(expiresAt.After(time.Now()))
 	assert.NoError(err)
 }
+
+func TestDefault_WithAuthURL(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/token", r.URL.Path)
+		assert.Equal("custom/scope", r.FormValue("scope"))
+
+		w.Write([]byte(`{"access_token":"foo","expires_in":"60"}`))
+	}))
+	defer ts.Close()
+
+	p := NewDefault(ts.Client(), "", "", false).
+		WithAuthURL(ts.URL + "/token").
+		WithScope("custom/scope")
+
+	token, _, err := p.Provide(context.Background())
+	assert.NoError(err)
+	assert.Equal("foo", token)
+}
+
+func TestDefault_Provide_tracing(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	p := NewDefault(ts.Client(), "", "", false).
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	p.authURL = ts.URL
+
+	_, _, err := p.Provide(context.Background())
+	assert.Error(err)
+
+	spans := recorder.Ended()
+	assert.Len(spans, 1)
+	assert.Equal("athenahealth token", spans[0].Name())
+	assert.Equal(codes.Error, spans[0].Status().Code)
+}
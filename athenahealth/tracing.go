@@ -0,0 +1,99 @@
+package athenahealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/stats"
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/tokenprovider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope name used for spans created by this package.
+const tracerName = "github.com/eleanorhealth/go-athenahealth/athenahealth"
+
+// Span attribute keys. Standard HTTP attributes follow the OpenTelemetry
+// semantic conventions.
+const (
+	attrHTTPRequestMethod      = attribute.Key("http.request.method")
+	attrHTTPResponseStatusCode = attribute.Key("http.response.status_code")
+	attrHTTPRequestResendCount = attribute.Key("http.request.resend_count")
+	attrURLTemplate            = attribute.Key("url.template")
+	attrPracticeID             = attribute.Key("athenahealth.practice_id")
+	attrXRequestID             = attribute.Key("athenahealth.x_request_id")
+	attrRateLimitWait          = attribute.Key("athenahealth.rate_limit.wait_ms")
+)
+
+// WithTracerProvider configures the OpenTelemetry TracerProvider used to trace
+// requests. By default the global TracerProvider is used.
+func (h *HTTPClient) WithTracerProvider(tracerProvider trace.TracerProvider) *HTTPClient {
+	h.tracerProvider = tracerProvider
+	h.tracer = tracerProvider.Tracer(tracerName)
+
+	if p, ok := h.tokenProvider.(*tokenprovider.Default); ok {
+		p.WithTracerProvider(tracerProvider)
+	}
+
+	return h
+}
+
+// WithPropagator configures the propagator used to inject trace context into
+// requests. By default the global TextMapPropagator is used.
+func (h *HTTPClient) WithPropagator(propagator propagation.TextMapPropagator) *HTTPClient {
+	h.propagator = propagator
+
+	return h
+}
+
+func defaultTracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// requestTrace collects what happened across all attempts of a request so it
+// can be recorded on the request's span.
+type requestTrace struct {
+	resends       int
+	rateLimitWait time.Duration
+}
+
+func (h *HTTPClient) startSpan(ctx context.Context, method, path, xRequestID string) (context.Context, trace.Span) {
+	urlTemplate := stats.CleanPath(path)
+
+	return h.tracer.Start(ctx, fmt.Sprintf("%s %s", method, urlTemplate),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attrHTTPRequestMethod.String(method),
+			attrURLTemplate.String(urlTemplate),
+			attrPracticeID.String(h.practiceID),
+			attrXRequestID.String(xRequestID),
+		),
+	)
+}
+
+// endSpan records the outcome of a request on span. Errors are redacted like
+// logs, since they can include athena detailed messages and query values.
+func (h *HTTPClient) endSpan(span trace.Span, rt *requestTrace, res *http.Response, err error) {
+	span.SetAttributes(
+		attrHTTPRequestResendCount.Int(rt.resends),
+		attrRateLimitWait.Int64(rt.rateLimitWait.Milliseconds()),
+	)
+
+	if res != nil {
+		span.SetAttributes(attrHTTPResponseStatusCode.Int(res.StatusCode))
+	}
+
+	if err != nil {
+		err = h.logRedactor().Error(err)
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,147 @@
+package athenahealth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eleanorhealth/go-athenahealth/athenahealth/ratelimiter"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestHTTPClient_request_tracing(t *testing.T) {
+	assert := assert.New(t)
+
+	var traceparent string
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	rateLimited := false
+	athenaClient.
+		WithTracerProvider(tracerProvider).
+		WithPropagator(propagation.TraceContext{}).
+		WithRetryPolicy(testRetryPolicy(2)).
+		WithRateLimiter(&testRateLimiter{
+			AllowedFunc: func(preview bool) (time.Duration, error) {
+				if rateLimited {
+					return 0, nil
+				}
+
+				rateLimited = true
+
+				return 10 * time.Millisecond, ratelimiter.ErrRateExceeded
+			},
+		})
+
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+
+	res, err := athenaClient.request(ctx, http.MethodGet, "/patients/123", nil, nil, nil)
+	parent.End()
+
+	assert.NoError(err)
+
+	spans := recorder.Ended()
+	assert.Len(spans, 2)
+
+	span := spans[0]
+	attrs := spanAttributes(span)
+
+	assert.Equal("GET /patients/:id:", span.Name())
+	assert.Equal(trace.SpanKindClient, span.SpanKind())
+	assert.Equal(parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(http.MethodGet, attrs[attrHTTPRequestMethod].AsString())
+	assert.Equal("/patients/:id:", attrs[attrURLTemplate].AsString())
+	assert.Equal(testPracticeID, attrs[attrPracticeID].AsString())
+	assert.Equal(res.Request.Header.Get(XRequestIDHeaderKey), attrs[attrXRequestID].AsString())
+	assert.Equal(int64(http.StatusOK), attrs[attrHTTPResponseStatusCode].AsInt64())
+	assert.Equal(int64(1), attrs[attrHTTPRequestResendCount].AsInt64())
+	assert.Equal(int64(10), attrs[attrRateLimitWait].AsInt64())
+
+	assert.Contains(traceparent, span.SpanContext().TraceID().String())
+	assert.Contains(traceparent, span.SpanContext().SpanID().String())
+}
+
+func TestHTTPClient_request_tracing_error(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	athenaClient.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, err := athenaClient.request(context.Background(), http.MethodGet, "/patients/123", nil, nil, nil)
+	assert.Error(err)
+
+	spans := recorder.Ended()
+	assert.Len(spans, 1)
+	assert.Equal(codes.Error, spans[0].Status().Code)
+	assert.Equal(int64(http.StatusNotFound), spanAttributes(spans[0])[attrHTTPResponseStatusCode].AsInt64())
+}
+
+func TestHTTPClient_request_tracing_redactsError(t *testing.T) {
+	assert := assert.New(t)
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid DOB","detailedmessage":"Jane Doe 01/02/1980"}`))
+	}
+
+	athenaClient, ts := testClient(h)
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	athenaClient.
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))).
+		WithLogRedactor(NewRedactor())
+
+	_, err := athenaClient.request(context.Background(), http.MethodGet, "/patients?firstname=Jane", nil, nil, nil)
+	assert.ErrorContains(err, "Jane Doe")
+
+	spans := recorder.Ended()
+	if !assert.Len(spans, 1) {
+		return
+	}
+
+	assert.Equal(codes.Error, spans[0].Status().Code)
+	assert.NotContains(spans[0].Status().Description, "Jane")
+
+	for _, event := range spans[0].Events() {
+		for _, attr := range event.Attributes {
+			assert.NotContains(attr.Value.Emit(), "Jane")
+		}
+	}
+}